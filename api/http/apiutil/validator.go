@@ -36,6 +36,12 @@ func getCustomMessage(err validator.FieldError) string {
 		return "Value is too short"
 	case "e164":
 		return "Invalid international phone format"
+	case "uuid":
+		return "Invalid UUID"
+	case "datetime":
+		return "Invalid date, use RFC3339 (e.g. 2026-01-02T15:04:05Z)"
+	case "max":
+		return "Value exceeds the maximum"
 	default:
 		return "Invalid value"
 	}
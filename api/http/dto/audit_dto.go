@@ -0,0 +1,86 @@
+// Package dto
+// this one has the audit log request and response shapes
+package dto
+
+import "time"
+
+// AuditQuery holds the audit log query string parameters
+type AuditQuery struct {
+	ActorID      string `validate:"omitempty,uuid"`
+	EventType    string `validate:"omitempty,max=64"`
+	From         string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To           string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	PayloadKey   string `validate:"omitempty,max=64"`
+	PayloadValue string `validate:"omitempty,max=256"`
+	Cursor       string
+	Limit        int `validate:"min=0,max=500"`
+}
+
+// AuditResponse is one audit log entry
+type AuditResponse struct {
+	ID        string         `json:"id"`
+	EventType string         `json:"event_type"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	Payload   map[string]any `json:"payload,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// AuditPageMeta carries the cursor for the next page, empty on the last page
+type AuditPageMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ChainBreakResponse pinpoints where the audit hash chain breaks
+type ChainBreakResponse struct {
+	Seq     int64  `json:"seq"`
+	AuditID string `json:"audit_id,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// ChainReportResponse is the outcome of an audit chain verification
+type ChainReportResponse struct {
+	From                time.Time           `json:"from"`
+	To                  time.Time           `json:"to"`
+	Valid               bool                `json:"valid"`
+	RowsChecked         int                 `json:"rows_checked"`
+	FirstSeq            int64               `json:"first_seq,omitempty"`
+	LastSeq             int64               `json:"last_seq,omitempty"`
+	CheckpointsVerified int                 `json:"checkpoints_verified"`
+	Broken              *ChainBreakResponse `json:"broken,omitempty"`
+}
+
+// DeadLetterResponse is an event a subscriber gave up on, replaying it only reaches that subscriber
+type DeadLetterResponse struct {
+	Seq        uint64    `json:"seq"`
+	Consumer   string    `json:"consumer"`
+	Subject    string    `json:"subject"`
+	Reason     string    `json:"reason"`
+	Deliveries int       `json:"deliveries"`
+	FailedAt   time.Time `json:"failed_at"`
+	// Payload is the original JSON event, or the raw text when it isn't valid JSON
+	Payload any `json:"payload" swaggertype:"object"`
+}
+
+// DeadLetterPageMeta carries the sequence to pass as after for the next page, empty on the last page
+type DeadLetterPageMeta struct {
+	NextAfter uint64 `json:"next_after,omitempty"`
+}
+
+// IngestStatsResponse shows how the audit worker keeps up with the event stream
+type IngestStatsResponse struct {
+	Workers     int    `json:"workers"`
+	BatchSize   int    `json:"batch_size"`
+	Connected   bool   `json:"connected"`
+	Lag         uint64 `json:"lag"`
+	InFlight    int    `json:"in_flight"`
+	Redelivered int    `json:"redelivered"`
+
+	Stored          uint64     `json:"stored"`
+	Batches         uint64     `json:"batches"`
+	FallbackBatches uint64     `json:"fallback_batches"`
+	Retried         uint64     `json:"retried"`
+	DeadLettered    uint64     `json:"dead_lettered"`
+	PerSecond       float64    `json:"per_second"`
+	LastBatchAt     *time.Time `json:"last_batch_at,omitempty"`
+	LastBatchMs     int64      `json:"last_batch_ms"`
+}
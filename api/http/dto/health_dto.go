@@ -0,0 +1,21 @@
+// Package dto
+// this one has the health check response shapes
+package dto
+
+import "time"
+
+// HealthResponse is the service status with the state of every background worker
+type HealthResponse struct {
+	Status      string                 `json:"status"`
+	Service     string                 `json:"service"`
+	Environment string                 `json:"environment"`
+	Workers     []WorkerStatusResponse `json:"workers"`
+}
+
+type WorkerStatusResponse struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
@@ -17,7 +17,7 @@ type UpdateUserRequest struct {
 	UserName *string `json:"user_name,omitempty" validate:"omitempty,min=3,max=32" example:"hehe"`
 	Email    *string `json:"email,omitempty" validate:"omitempty,email" example:"hehe@hehemail.com"`
 	Phone    *string `json:"phone,omitempty" validate:"omitempty,e164" example:"+8801700000000"`
-	Status   *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended" example:"active"`
+	Status   *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive" example:"active"`
 }
 
 // UserResponse is what we send back
@@ -29,3 +29,85 @@ type UserResponse struct {
 	UserStatus string    `json:"user_status"`
 	CreatedAt  time.Time `json:"created_at"`
 }
+
+// UserExportRow is one line of a roster export, secrets are never part of it
+type UserExportRow struct {
+	ID         string     `json:"id"`
+	UserName   string     `json:"user_name"`
+	Email      string     `json:"email"`
+	Phone      string     `json:"phone"`
+	UserStatus string     `json:"user_status"`
+	UserRole   string     `json:"user_role"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UserExportColumns is the header row used by tabular export formats
+var UserExportColumns = []string{"id", "user_name", "email", "phone", "user_status", "user_role", "created_at", "updated_at", "deleted_at"}
+
+// Record flattens the row in UserExportColumns order
+func (r UserExportRow) Record() []string {
+	deletedAt := ""
+	if r.DeletedAt != nil {
+		deletedAt = r.DeletedAt.Format(time.RFC3339)
+	}
+	return []string{
+		r.ID,
+		r.UserName,
+		r.Email,
+		r.Phone,
+		r.UserStatus,
+		r.UserRole,
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+		deletedAt,
+	}
+}
+
+// SuspendUserRequest is what an admin sends to suspend a user, omit until for an indefinite suspension
+type SuspendUserRequest struct {
+	Reason string     `json:"reason" validate:"required,min=3,max=500" example:"Repeated no-shows"`
+	Until  *time.Time `json:"until,omitempty" example:"2026-12-31T00:00:00Z"`
+}
+
+// UnsuspendUserRequest is what an admin sends to lift a suspension early
+type UnsuspendUserRequest struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500" example:"Appeal accepted"`
+}
+
+// SuspensionResponse is one entry of a user's suspension history
+type SuspensionResponse struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	SuspendedBy string     `json:"suspended_by"`
+	Reason      string     `json:"reason"`
+	SuspendedAt time.Time  `json:"suspended_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LiftedAt    *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy    *string    `json:"lifted_by,omitempty"`
+	LiftReason  *string    `json:"lift_reason,omitempty"`
+}
+
+// PurgeTrashRequest asks to hard delete users trashed for longer than retention
+type PurgeTrashRequest struct {
+	Retention string `json:"retention" validate:"required" example:"720h"` // Go duration, e.g. 720h for 30 days
+	DryRun    bool   `json:"dry_run" example:"true"`
+}
+
+// TrashedUserResponse is a trashed user listed in a purge report
+type TrashedUserResponse struct {
+	ID        string     `json:"id"`
+	UserName  string     `json:"user_name"`
+	Email     string     `json:"email"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// PurgeReportResponse tells what a purge run deleted or would delete
+type PurgeReportResponse struct {
+	Cutoff     time.Time             `json:"cutoff"`
+	DryRun     bool                  `json:"dry_run"`
+	Candidates []TrashedUserResponse `json:"candidates"`
+	Purged     int                   `json:"purged"`
+	Failed     int                   `json:"failed"`
+}
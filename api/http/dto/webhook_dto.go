@@ -0,0 +1,54 @@
+// Package dto
+// This one has the webhook request and response shapes
+package dto
+
+import "time"
+
+// CreateWebhookRequest registers a partner endpoint, omit secret to have one generated
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048" example:"https://billing.example.com/hooks/users"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,max=50,dive,required,max=128" example:"user.created,user.suspended,user.deleted"`
+	Secret      string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Description string   `json:"description,omitempty" validate:"omitempty,max=500" example:"Billing platform"`
+}
+
+// UpdateWebhookRequest changes only the fields that are sent
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url,omitempty" validate:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1,max=50,dive,required,max=128"`
+	Secret      *string  `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponse never carries the secret
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookCreatedResponse shows the signing secret, this is the only time it is returned
+type WebhookCreatedResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryResponse is one entry of a webhook's delivery log
+type WebhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status" example:"pending"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
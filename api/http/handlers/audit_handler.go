@@ -0,0 +1,258 @@
+// Package handlers
+// This one holds the audit log handlers
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/apiutil"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+)
+
+type AuditHandler struct {
+	svc      ports.AuditService
+	shutdown <-chan struct{}
+}
+
+// NewAuditHandler ends open streams once shutdown is closed, the server waits for them otherwise
+func NewAuditHandler(svc ports.AuditService, shutdown <-chan struct{}) *AuditHandler {
+	return &AuditHandler{svc: svc, shutdown: shutdown}
+}
+
+// List godoc
+// @Summary      Query the audit log
+// @Description  Reads audit entries newest first with cursor pagination. The time range defaults to the last 30 days and may span at most 366 days.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        actor_id       query     string  false  "Filter by actor UUID"
+// @Param        event_type     query     string  false  "Filter by event type (e.g. USER_LOGIN)"
+// @Param        from           query     string  false  "Lower bound on created_at, RFC3339 (inclusive)"
+// @Param        to             query     string  false  "Upper bound on created_at, RFC3339 (exclusive)"
+// @Param        payload_key    query     string  false  "Payload key that must match payload_value"
+// @Param        payload_value  query     string  false  "Payload value to match, numbers and true or false also match the JSON number or boolean"
+// @Param        cursor         query     string  false  "next_cursor from the previous page"
+// @Param        limit          query     int     false  "Page size (default 50, max 500)"
+// @Success      200  {array}   dto.AuditResponse
+// @Failure      400  {object}  jsonutil.Response "Invalid filter"
+// @Router       /admin/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.readFilter(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.svc.Query(r.Context(), filter)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := make([]dto.AuditResponse, len(page.Items))
+	for i, a := range page.Items {
+		res[i] = h.mapToResponse(a)
+	}
+
+	meta := dto.AuditPageMeta{}
+	if page.Next != nil {
+		meta.NextCursor = encodeAuditCursor(*page.Next)
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, res, meta, "Audit log retrieved")
+}
+
+// Verify godoc
+// @Summary      Verify the audit hash chain
+// @Description  Walks the tamper evident hash chain over a time range and reports the first broken link. The range defaults to the last 30 days.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "Lower bound on created_at, RFC3339 (inclusive)"
+// @Param        to    query     string  false  "Upper bound on created_at, RFC3339 (exclusive)"
+// @Success      200   {object}  dto.ChainReportResponse
+// @Failure      400   {object}  jsonutil.Response "Invalid range"
+// @Router       /admin/audit/verify [get]
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	for _, p := range []struct {
+		key    string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := r.URL.Query().Get(p.key)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+				{Field: p.key, Message: "Invalid date, use RFC3339 (e.g. 2026-01-02T15:04:05Z)"},
+			})
+			return
+		}
+		*p.target = t
+	}
+
+	report, err := h.svc.Verify(r.Context(), from, to)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := MapChainReport(report)
+	message := "Audit chain intact"
+	if !res.Valid {
+		message = "Audit chain broken"
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, res, nil, message)
+}
+
+// MapChainReport converts a verification report for the API and the verify command
+func MapChainReport(report *domain.ChainReport) dto.ChainReportResponse {
+	res := dto.ChainReportResponse{
+		From:                report.From,
+		To:                  report.To,
+		Valid:               report.Broken == nil,
+		RowsChecked:         report.RowsChecked,
+		FirstSeq:            report.FirstSeq,
+		LastSeq:             report.LastSeq,
+		CheckpointsVerified: report.CheckpointsVerified,
+	}
+	if report.Broken != nil {
+		res.Broken = &dto.ChainBreakResponse{
+			Seq:     report.Broken.Seq,
+			AuditID: report.Broken.AuditUUID,
+			Reason:  report.Broken.Reason,
+		}
+	}
+	return res
+}
+
+// readFilter parses and validates the query string, writing the error response itself on failure
+func (h *AuditHandler) readFilter(w http.ResponseWriter, r *http.Request) (domain.AuditFilter, bool) {
+	q := r.URL.Query()
+	params := dto.AuditQuery{
+		ActorID:      q.Get("actor_id"),
+		EventType:    q.Get("event_type"),
+		From:         q.Get("from"),
+		To:           q.Get("to"),
+		PayloadKey:   q.Get("payload_key"),
+		PayloadValue: q.Get("payload_value"),
+		Cursor:       q.Get("cursor"),
+		Limit:        ParseQueryInt(r, "limit", 0),
+	}
+
+	if errs := apiutil.ValidateStruct(params); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return domain.AuditFilter{}, false
+	}
+
+	filter := domain.AuditFilter{
+		ActorID:      params.ActorID,
+		EventType:    params.EventType,
+		PayloadKey:   params.PayloadKey,
+		PayloadValue: params.PayloadValue,
+		Limit:        params.Limit,
+	}
+
+	// Formats were checked by the validator above
+	if params.From != "" {
+		filter.From, _ = time.Parse(time.RFC3339, params.From)
+	}
+	if params.To != "" {
+		filter.To, _ = time.Parse(time.RFC3339, params.To)
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeAuditCursor(params.Cursor)
+		if err != nil {
+			jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+				{Field: "cursor", Message: "Invalid cursor"},
+			})
+			return domain.AuditFilter{}, false
+		}
+		filter.After = &cursor
+	}
+
+	return filter, true
+}
+
+func (h *AuditHandler) mapToResponse(a *domain.Audit) dto.AuditResponse {
+	return dto.AuditResponse{
+		ID:        a.UUID,
+		EventType: a.EventType,
+		ActorID:   a.ActorID,
+		Payload:   a.Payload,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// encodeAuditCursor turns a cursor into an opaque URL safe token
+func encodeAuditCursor(c domain.AuditCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuditCursor(token string) (domain.AuditCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return domain.AuditCursor{}, err
+	}
+
+	micros, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return domain.AuditCursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	ts, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return domain.AuditCursor{}, err
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return domain.AuditCursor{}, err
+	}
+
+	return domain.AuditCursor{CreatedAt: time.UnixMicro(ts), ID: n}, nil
+}
+
+// Ingestion godoc
+// @Summary      Audit ingestion lag and throughput
+// @Description  Lag is the number of audit events waiting in the stream. Counters run since the process started, per_second averages the last minute.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.IngestStatsResponse
+// @Router       /admin/audit/ingestion [get]
+func (h *AuditHandler) Ingestion(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.svc.IngestStats(r.Context())
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, dto.IngestStatsResponse{
+		Workers:         stats.Workers,
+		BatchSize:       stats.BatchSize,
+		Connected:       stats.Connected,
+		Lag:             stats.Pending,
+		InFlight:        stats.InFlight,
+		Redelivered:     stats.Redelivered,
+		Stored:          stats.Stored,
+		Batches:         stats.Batches,
+		FallbackBatches: stats.FallbackBatches,
+		Retried:         stats.Retried,
+		DeadLettered:    stats.DeadLettered,
+		PerSecond:       stats.PerSecond,
+		LastBatchAt:     stats.LastBatchAt,
+		LastBatchMs:     stats.LastBatchTook.Milliseconds(),
+	}, nil, "Audit ingestion stats retrieved")
+}
@@ -0,0 +1,109 @@
+// Package handlers
+// This one streams the audit log live over Server-Sent Events
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+)
+
+// Idle connections get a comment line this often so proxies don't time them out
+const sseHeartbeat = 15 * time.Second
+
+// Stream godoc
+// @Summary      Follow the audit log live
+// @Description  Server-Sent Events stream of new audit events. Each event's id is its stream sequence; reconnecting with Last-Event-ID resumes right after it. from replays from a point in time, to ends the stream. Heartbeat comments are sent every 15 seconds.
+// @Tags         admin
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        actor_id       query     string  false  "Filter by actor UUID"
+// @Param        event_type     query     string  false  "Filter by event type (e.g. USER_LOGIN)"
+// @Param        from           query     string  false  "Replay events published since, RFC3339"
+// @Param        to             query     string  false  "End the stream at, RFC3339"
+// @Param        payload_key    query     string  false  "Payload key that must match payload_value"
+// @Param        payload_value  query     string  false  "Payload value to match, numbers and true or false also match the JSON number or boolean"
+// @Param        Last-Event-ID  header    int     false  "Resume after this stream sequence"
+// @Success      200  {object}  dto.AuditResponse "One event per message"
+// @Failure      400  {object}  jsonutil.Response "Invalid filter"
+// @Failure      503  {object}  jsonutil.Response "Live stream unavailable"
+// @Router       /admin/audit/stream [get]
+func (h *AuditHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.readFilter(w, r)
+	if !ok {
+		return
+	}
+
+	var afterSeq uint64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+				{Field: "Last-Event-ID", Message: "Must be a sequence number"},
+			})
+			return
+		}
+		afterSeq = n
+	}
+
+	events, err := h.svc.Stream(r.Context(), filter, afterSeq)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	// The stream outlives the server wide write timeout, lift it for this response only
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	// Returning cancels the request context, which deletes the consumer behind events
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.shutdown:
+			// Clients reconnect with Last-Event-ID, to another instance or to this one once it's back
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, open := <-events:
+			if !open {
+				return
+			}
+
+			res := h.mapToResponse(&e.Audit)
+			if res.CreatedAt.IsZero() {
+				// Not stored yet, publish time is the closest thing to a timestamp
+				res.CreatedAt = e.PublishedAt
+			}
+
+			data, err := json.Marshal(res)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: audit\ndata: %s\n\n", e.Seq, data); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
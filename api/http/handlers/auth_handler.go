@@ -27,8 +27,11 @@ func NewAuthHandler(svc ports.AuthService) *AuthHandler {
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Param        user  body      dto.RegisterUserRequest  true  "User Data"
+// @Param        user             body      dto.RegisterUserRequest  true   "User Data"
+// @Param        Idempotency-Key  header    string                   false  "Retries with the same key replay the first response"
 // @Success      201   {object}  dto.UserResponse
+// @Failure      409   {object}  jsonutil.Response  "Same key still in flight"
+// @Failure      422   {object}  jsonutil.Response  "Key reused with a different body"
 // @Router       /auth/register [post]
 func (a *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterUserRequest
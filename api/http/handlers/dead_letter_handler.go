@@ -0,0 +1,105 @@
+// Package handlers
+// This one holds the audit dead letter queue handlers
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+	"github.com/go-chi/chi/v5"
+)
+
+// DeadLetters godoc
+// @Summary      List dead lettered events
+// @Description  Events a subscriber failed too often or could not decode, oldest first. consumer names the subscriber.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        after  query     int  false  "Only events after this sequence (next_after from the previous page)"
+// @Param        limit  query     int  false  "Page size (default 50, max 500)"
+// @Success      200    {array}   dto.DeadLetterResponse
+// @Failure      400    {object}  jsonutil.Response "Invalid data"
+// @Router       /admin/audit/dlq [get]
+func (h *AuditHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	var after uint64
+	if raw := r.URL.Query().Get("after"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+				{Field: "after", Message: "Must be a sequence number"},
+			})
+			return
+		}
+		after = n
+	}
+
+	filter := domain.DeadLetterFilter{
+		AfterSeq: after,
+		Limit:    ParseQueryInt(r, "limit", 0),
+	}
+
+	list, err := h.svc.DeadLetters(r.Context(), filter)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := make([]dto.DeadLetterResponse, len(list))
+	for i, dl := range list {
+		res[i] = mapDeadLetter(dl)
+	}
+
+	meta := dto.DeadLetterPageMeta{}
+	if len(list) > 0 && len(list) == filter.Limit {
+		meta.NextAfter = list[len(list)-1].Seq
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, res, meta, "Dead letters retrieved")
+}
+
+// ReplayDeadLetter godoc
+// @Summary      Replay a dead lettered event
+// @Description  Sends the event back to the subscriber that failed it and removes it from the dead letter queue
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        seq  path      int  true  "Dead letter sequence"
+// @Success      200  {object}  dto.DeadLetterResponse
+// @Failure      400  {object}  jsonutil.Response "Invalid sequence"
+// @Failure      404  {object}  jsonutil.Response "Dead letter not found"
+// @Router       /admin/audit/dlq/{seq}/replay [post]
+func (h *AuditHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.ParseUint(chi.URLParam(r, "seq"), 10, 64)
+	if err != nil || seq == 0 {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	dl, err := h.svc.ReplayDeadLetter(r.Context(), seq)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, mapDeadLetter(dl), nil, "Dead letter replayed")
+}
+
+func mapDeadLetter(dl *domain.DeadLetter) dto.DeadLetterResponse {
+	res := dto.DeadLetterResponse{
+		Seq:        dl.Seq,
+		Consumer:   dl.Consumer,
+		Subject:    dl.Subject,
+		Reason:     dl.Reason,
+		Deliveries: dl.Deliveries,
+		FailedAt:   dl.FailedAt,
+		Payload:    string(dl.Payload),
+	}
+	if json.Valid(dl.Payload) {
+		res.Payload = json.RawMessage(dl.Payload)
+	}
+	return res
+}
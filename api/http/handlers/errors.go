@@ -28,9 +28,30 @@ func HandleError(w http.ResponseWriter, err error) {
 		case domain.CodeNotFound:
 			jsonutil.NotFoundResponse(w, appErr.Message)
 		case domain.CodeValidation:
-			jsonutil.BadRequestResponse(w, appErr.Message, nil)
+			var items []jsonutil.ErrorItem
+			for _, e := range appErr.Errors {
+				items = append(items, jsonutil.ErrorItem{Field: e.Field, Message: e.Message})
+			}
+			jsonutil.BadRequestResponse(w, appErr.Message, items)
 		case domain.CodeUauthorized:
 			jsonutil.UnauthorizedResponse(w, appErr.Message)
+		case domain.CodeSuspended:
+			items := make([]jsonutil.ErrorItem, 0, len(appErr.Errors))
+			for _, e := range appErr.Errors {
+				items = append(items, jsonutil.ErrorItem{Code: string(appErr.Code), Field: e.Field, Message: e.Message})
+			}
+			jsonutil.ErrorResponse(w, http.StatusForbidden, appErr.Message, items)
+		case domain.CodeIdempotencyKeyReused:
+			items := []jsonutil.ErrorItem{{Code: string(appErr.Code), Field: appErr.Field, Message: appErr.Message}}
+			jsonutil.ErrorResponse(w, http.StatusUnprocessableEntity, appErr.Message, items)
+		case domain.CodeIdempotencyInFlight:
+			items := []jsonutil.ErrorItem{{Code: string(appErr.Code), Field: appErr.Field, Message: appErr.Message}}
+			jsonutil.ErrorResponse(w, http.StatusConflict, appErr.Message, items)
+		case domain.CodeRateLimited:
+			items := []jsonutil.ErrorItem{{Code: string(appErr.Code), Message: appErr.Message}}
+			jsonutil.ErrorResponse(w, http.StatusTooManyRequests, appErr.Message, items)
+		case domain.CodeUnavailable:
+			jsonutil.ErrorResponse(w, http.StatusServiceUnavailable, appErr.Message, nil)
 
 		default:
 			jsonutil.ServerErrorResponse(w, appErr.Err)
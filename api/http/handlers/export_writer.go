@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/xlsxutil"
+)
+
+// Supported export formats and their response headers
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv"},
+	"ndjson": {"application/x-ndjson", "ndjson"},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+}
+
+// exportWriter encodes export rows in one of the supported formats
+type exportWriter interface {
+	WriteRow(row dto.UserExportRow) error
+	Flush() error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case "ndjson":
+		return &ndjsonExport{enc: json.NewEncoder(w)}, nil
+	case "xlsx":
+		xw, err := xlsxutil.NewWriter(w, "users")
+		if err != nil {
+			return nil, err
+		}
+		if err := xw.Write(dto.UserExportColumns); err != nil {
+			return nil, err
+		}
+		return &xlsxExport{w: xw}, nil
+	default:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(dto.UserExportColumns); err != nil {
+			return nil, err
+		}
+		return &csvExport{w: cw}, nil
+	}
+}
+
+type csvExport struct{ w *csv.Writer }
+
+func (c *csvExport) WriteRow(row dto.UserExportRow) error {
+	return c.w.Write(sanitizeCells(row.Record()))
+}
+
+func (c *csvExport) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExport) Close() error { return c.Flush() }
+
+type ndjsonExport struct{ enc *json.Encoder }
+
+func (n *ndjsonExport) WriteRow(row dto.UserExportRow) error { return n.enc.Encode(row) }
+func (n *ndjsonExport) Flush() error                         { return nil }
+func (n *ndjsonExport) Close() error                         { return nil }
+
+type xlsxExport struct{ w *xlsxutil.Writer }
+
+// Cells are inline strings, spreadsheets never evaluate those as formulas so values go in raw
+func (x *xlsxExport) WriteRow(row dto.UserExportRow) error {
+	return x.w.Write(row.Record())
+}
+
+func (x *xlsxExport) Flush() error { return x.w.Flush() }
+func (x *xlsxExport) Close() error { return x.w.Close() }
+
+// sanitizeCells defuses formula injection when a CSV export is opened in a spreadsheet.
+// A leading + or - starts a formula too, so phone numbers like +8801... get the quote as well.
+func sanitizeCells(record []string) []string {
+	for i, v := range record {
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			record[i] = "'" + v
+		}
+	}
+	return record
+}
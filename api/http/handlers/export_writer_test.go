@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+)
+
+var exportTestRow = dto.UserExportRow{
+	ID:         "0192f0c4-0000-7000-8000-000000000001",
+	UserName:   "=HYPERLINK(\"http://evil\")",
+	Email:      "@sum@example.com",
+	Phone:      "+8801712345678",
+	UserStatus: "-active",
+	UserRole:   "user",
+	CreatedAt:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	UpdatedAt:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+}
+
+// writeExport writes the test row in format and returns the encoded bytes
+func writeExport(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newExportWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(exportTestRow); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVExportEscapesFormulas(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeExport(t, "csv"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || !reflect.DeepEqual(records[0], dto.UserExportColumns) {
+		t.Fatalf("got %v", records)
+	}
+
+	want := []string{
+		exportTestRow.ID,
+		`'=HYPERLINK("http://evil")`,
+		"'@sum@example.com",
+		"'+8801712345678",
+		"'-active",
+		"user",
+		"2026-10-19T00:00:00Z",
+		"2026-10-19T00:00:00Z",
+		"",
+	}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Fatalf("row\ngot  %q\nwant %q", records[1], want)
+	}
+}
+
+func TestXLSXExportKeepsCellsRaw(t *testing.T) {
+	data := writeExport(t, "xlsx")
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type string `xml:"t,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.NewDecoder(f).Decode(&sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("%d rows, want 2", len(sheet.Rows))
+	}
+
+	var got []string
+	for _, c := range sheet.Rows[1].Cells {
+		if c.Type != "inlineStr" {
+			t.Fatalf("cell of type %q, only inline strings are safe to write raw", c.Type)
+		}
+		got = append(got, c.Text)
+	}
+	if want := exportTestRow.Record(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("row\ngot  %q\nwant %q", got, want)
+	}
+}
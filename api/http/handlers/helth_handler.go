@@ -6,34 +6,63 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
 	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
 )
 
 type HealthHandler struct {
 	// Keep DB connection interface herre
+	workers ports.WorkerMonitor
 }
 
-func NewHealthHandleer() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandleer(workers ports.WorkerMonitor) *HealthHandler {
+	return &HealthHandler{workers: workers}
 }
 
 // HealthCheck handles the GET /api/v1/health request.
 // @Summary Check the status and uptime of the API server.
-// @Description Provides a simple UP/DOWN status and service identification.
+// @Description Reports UP, DEGRADED when a background worker is restarting after a crash, or DRAINING with a 503 once shutdown has started.
 // @Tags System
 // @Accept json
 // @Produce json
-// @Success 200 {object} object{status=string,service=string,environment=string} "Successful response indicating API status is UP."
+// @Success 200 {object} dto.HealthResponse "API is serving requests."
+// @Failure 503 {object} dto.HealthResponse "API is shutting down."
 // @Router /health [get]
 func (h *HealthHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	// Data payload for the response
-	response := map[string]string{
-		"status":      "UP",
-		"service":     "DocPad API",
-		"environment": "development",
+	response := dto.HealthResponse{
+		Status:      "UP",
+		Service:     "DocPad API",
+		Environment: "development",
+		Workers:     []dto.WorkerStatusResponse{},
 	}
 
-	if err := jsonutil.WriteJSON(w, http.StatusOK, response, nil, "Health is good!"); err != nil {
+	for _, ws := range h.workers.Workers() {
+		if ws.State == domain.WorkerRestarting {
+			response.Status = "DEGRADED"
+		}
+		response.Workers = append(response.Workers, dto.WorkerStatusResponse{
+			Name:      ws.Name,
+			State:     string(ws.State),
+			Restarts:  ws.Restarts,
+			LastError: ws.LastError,
+			StartedAt: ws.StartedAt,
+		})
+	}
+
+	status, message := http.StatusOK, "Health is good!"
+	switch {
+	case h.workers.Draining():
+		// Load balancers should stop routing here while the workers drain
+		response.Status = "DRAINING"
+		status, message = http.StatusServiceUnavailable, "Shutting down"
+	case response.Status == "DEGRADED":
+		message = "Background workers are restarting"
+	}
+
+	if err := jsonutil.WriteJSON(w, status, response, nil, message); err != nil {
 		log.Printf("ERROR in helth check %v", err)
 	}
 }
@@ -0,0 +1,159 @@
+// Package handlers
+// This one holds the user suspension handlers
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/apiutil"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/middleware"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+)
+
+// Suspend godoc
+// @Summary      Suspend a user
+// @Description  Suspends an active user with a reason. Omit until for an indefinite suspension.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "User ID"
+// @Param        request  body      dto.SuspendUserRequest  true  "Suspension details"
+// @Success      201      {object}  dto.SuspensionResponse
+// @Failure      400      {object}  jsonutil.Response "Invalid data"
+// @Failure      404      {object}  jsonutil.Response "User not found"
+// @Failure      409      {object}  jsonutil.Response "Already suspended"
+// @Router       /admin/users/{id}/suspend [post]
+func (h *UserHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	id, err := ReadIDParam(r)
+	if err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	var req dto.SuspendUserRequest
+	if err := jsonutil.ReadJSON(w, r, &req); err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	if errs := apiutil.ValidateStruct(req); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(domain.UserClaims)
+	if !ok {
+		jsonutil.UnauthorizedResponse(w, "Unauthorized: No claims found")
+		return
+	}
+
+	sus, err := h.svc.SuspendUser(r.Context(), domain.SuspendUser{
+		UserID:  id,
+		ActorID: claims.UserID,
+		Reason:  req.Reason,
+		Until:   req.Until,
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, h.mapSuspension(sus), nil, "User suspended")
+}
+
+// Unsuspend godoc
+// @Summary      Lift a user's suspension
+// @Description  Reactivates a suspended user before the suspension expires
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                    true  "User ID"
+// @Param        request  body      dto.UnsuspendUserRequest  true  "Reason for lifting"
+// @Success      200      {object}  dto.UserResponse
+// @Failure      400      {object}  jsonutil.Response "User is not suspended"
+// @Failure      404      {object}  jsonutil.Response "User not found"
+// @Router       /admin/users/{id}/unsuspend [post]
+func (h *UserHandler) Unsuspend(w http.ResponseWriter, r *http.Request) {
+	id, err := ReadIDParam(r)
+	if err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	var req dto.UnsuspendUserRequest
+	if err := jsonutil.ReadJSON(w, r, &req); err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	if errs := apiutil.ValidateStruct(req); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(domain.UserClaims)
+	if !ok {
+		jsonutil.UnauthorizedResponse(w, "Unauthorized: No claims found")
+		return
+	}
+
+	user, err := h.svc.UnsuspendUser(r.Context(), domain.UnsuspendUser{
+		UserID:  id,
+		ActorID: claims.UserID,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, h.mapToResponse(user), nil, "User suspension lifted")
+}
+
+// Suspensions godoc
+// @Summary      Suspension history of a user
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {array}   dto.SuspensionResponse
+// @Failure      404  {object}  jsonutil.Response "User not found"
+// @Router       /admin/users/{id}/suspensions [get]
+func (h *UserHandler) Suspensions(w http.ResponseWriter, r *http.Request) {
+	id, err := ReadIDParam(r)
+	if err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	list, err := h.svc.SuspensionHistory(r.Context(), id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := make([]dto.SuspensionResponse, len(list))
+	for i, s := range list {
+		res[i] = h.mapSuspension(s)
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, res, nil, "Suspension history retrieved")
+}
+
+func (h *UserHandler) mapSuspension(s *domain.Suspension) dto.SuspensionResponse {
+	return dto.SuspensionResponse{
+		ID:          s.UUID,
+		UserID:      s.UserID,
+		SuspendedBy: s.SuspendedBy,
+		Reason:      s.Reason,
+		SuspendedAt: s.SuspendedAt,
+		ExpiresAt:   s.ExpiresAt,
+		LiftedAt:    s.LiftedAt,
+		LiftedBy:    s.LiftedBy,
+		LiftReason:  s.LiftReason,
+	}
+}
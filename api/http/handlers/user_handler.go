@@ -3,11 +3,15 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/api/http/apiutil"
 	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/middleware"
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
 	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
@@ -27,8 +31,11 @@ func NewUserHandler(svc ports.UserService) *UserHandler {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        user  body      dto.RegisterUserRequest  true  "User Data"
+// @Param        user             body      dto.RegisterUserRequest  true   "User Data"
+// @Param        Idempotency-Key  header    string                   false  "Retries with the same key replay the first response"
 // @Success      201   {object}  dto.UserResponse
+// @Failure      409   {object}  jsonutil.Response  "Same key still in flight"
+// @Failure      422   {object}  jsonutil.Response  "Key reused with a different body"
 // @Router       /user [post]
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterUserRequest
@@ -98,6 +105,122 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 	jsonutil.WriteJSON(w, http.StatusOK, h.mapSliceToResponse(users), nil, "Active users retrieved")
 }
 
+// Export godoc
+// @Summary      Export the user roster
+// @Description  Streams users matching the list filters as CSV, NDJSON or XLSX. Password hashes and OTPs are never included.
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format       query     string  false  "Export format (csv, ndjson, xlsx), default csv"
+// @Param        user_name    query     string  false  "Filter by username"
+// @Param        email        query     string  false  "Filter by email"
+// @Param        phone        query     string  false  "Filter by phone"
+// @Param        user_status  query     string  false  "Filter by status (e.g. active, inactive)"
+// @Param        show_deleted query     bool    false  "Include deleted users (true/false)"
+// @Param        limit        query     int     false  "Maximum number of rows (default all)"
+// @Param        offset       query     int     false  "Number of rows to skip (default 0)"
+// @Security     BearerAuth
+// @Success      200  {file}    file
+// @Failure      400  {object}  jsonutil.Response "Unsupported format"
+// @Failure      403  {object}  jsonutil.Response "Forbidden"
+// @Router       /admin/users/export [get]
+func (h *UserHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	spec, ok := exportFormats[format]
+	if !ok {
+		jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+			{Field: "format", Message: "Must be one of csv, ndjson, xlsx"},
+		})
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(domain.UserClaims)
+	if !ok {
+		jsonutil.UnauthorizedResponse(w, "Unauthorized: No claims found")
+		return
+	}
+
+	req := domain.UserExport{
+		ActorID: claims.UserID,
+		Format:  format,
+		Filter: domain.UserFilter{
+			UserName:    r.URL.Query().Get("user_name"),
+			Email:       r.URL.Query().Get("email"),
+			Phone:       r.URL.Query().Get("phone"),
+			UserStatus:  r.URL.Query().Get("user_status"),
+			ShowDeleted: ParseQueryBool(r, "show_deleted", false),
+			Limit:       ParseQueryInt(r, "limit", 0), // Exports are unbounded unless asked
+			Offset:      ParseQueryInt(r, "offset", 0),
+		},
+	}
+
+	// Exports outlive the server wide write timeout, lift it for this response only
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102-150405"), spec.extension)
+	w.Header().Set("Content-Type", spec.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	const flushEvery = 500
+
+	var ew exportWriter
+	written := 0
+	rows, err := h.svc.ExportUsers(r.Context(), req, func(u *domain.User) error {
+		// Headers are committed lazily so a failure before the first row can still be reported as JSON
+		if ew == nil {
+			var err error
+			if ew, err = newExportWriter(format, w); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.WriteRow(h.mapToExportRow(u)); err != nil {
+			return err
+		}
+
+		// Push rows out regularly so the client sees progress and memory stays flat
+		written++
+		if written%flushEvery == 0 {
+			if err := ew.Flush(); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+		return nil
+	})
+
+	if err != nil && ew == nil {
+		HandleError(w, err)
+		return
+	}
+
+	if ew == nil {
+		// No rows matched, still send a well formed (empty) document
+		if ew, err = newExportWriter(format, w); err != nil {
+			slog.Error("Export writer init failed", "error", err)
+			return
+		}
+	}
+
+	if err != nil {
+		// The status line is already out, all we can do is cut the stream short and log it
+		slog.Error("User export aborted", "format", format, "rows", rows, "error", err)
+		return
+	}
+
+	if err := ew.Close(); err != nil {
+		slog.Error("User export close failed", "format", format, "error", err)
+		return
+	}
+	_ = rc.Flush()
+}
+
 // GetByID godoc
 // @Summary      Get user by ID
 // @Tags         user
@@ -278,6 +401,76 @@ func (h *UserHandler) Prune(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// PurgeTrash godoc
+// @Summary      Purge old trashed users
+// @Description  Hard deletes users trashed for longer than the retention period. With dry_run the users are only reported.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.PurgeTrashRequest  true  "Retention and dry run flag"
+// @Success      200      {object}  dto.PurgeReportResponse
+// @Failure      400      {object}  jsonutil.Response "Invalid data"
+// @Router       /admin/users/trash/purge [post]
+func (h *UserHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	var req dto.PurgeTrashRequest
+	if err := jsonutil.ReadJSON(w, r, &req); err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	if errs := apiutil.ValidateStruct(req); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return
+	}
+
+	retention, err := time.ParseDuration(req.Retention)
+	if err != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+			{Field: "retention", Message: "Must be a duration such as 720h"},
+		})
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(domain.UserClaims)
+	if !ok {
+		jsonutil.UnauthorizedResponse(w, "Unauthorized: No claims found")
+		return
+	}
+
+	report, err := h.svc.PurgeTrash(r.Context(), domain.TrashPurge{
+		ActorID:   claims.UserID,
+		Retention: retention,
+		DryRun:    req.DryRun,
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := dto.PurgeReportResponse{
+		Cutoff:     report.Cutoff,
+		DryRun:     report.DryRun,
+		Candidates: make([]dto.TrashedUserResponse, len(report.Candidates)),
+		Purged:     report.Purged,
+		Failed:     report.Failed,
+	}
+	for i, u := range report.Candidates {
+		res.Candidates[i] = dto.TrashedUserResponse{
+			ID:        u.UUID,
+			UserName:  u.UserName,
+			Email:     u.Email,
+			DeletedAt: u.DeletedAt,
+		}
+	}
+
+	message := "Trash purged"
+	if report.DryRun {
+		message = "Trash purge dry run"
+	}
+	jsonutil.WriteJSON(w, http.StatusOK, res, nil, message)
+}
+
 // --- MAPPING HELPERS ---
 
 func (h *UserHandler) mapToResponse(u *domain.User) dto.UserResponse {
@@ -291,6 +484,20 @@ func (h *UserHandler) mapToResponse(u *domain.User) dto.UserResponse {
 	}
 }
 
+func (h *UserHandler) mapToExportRow(u *domain.User) dto.UserExportRow {
+	return dto.UserExportRow{
+		ID:         u.UUID,
+		UserName:   u.UserName,
+		Email:      u.Email,
+		Phone:      u.Phone,
+		UserStatus: u.UserStatus,
+		UserRole:   u.UserRole,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+		DeletedAt:  u.DeletedAt,
+	}
+}
+
 func (h *UserHandler) mapSliceToResponse(users []*domain.User) []dto.UserResponse {
 	res := make([]dto.UserResponse, len(users))
 	for i, u := range users {
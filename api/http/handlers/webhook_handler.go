@@ -0,0 +1,267 @@
+// Package handlers
+// This one holds the webhook subscription handlers
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/apiutil"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/dto"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookHandler struct {
+	svc ports.WebhookService
+}
+
+func NewWebhookHandler(svc ports.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// Create godoc
+// @Summary      Register a webhook
+// @Description  Subscribes a partner URL to event types (patterns like user.* are allowed). Deliveries are signed with HMAC-SHA256 over "<Webhook-Timestamp>.<body>" in the Webhook-Signature header. The secret is only returned here.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      dto.CreateWebhookRequest  true  "Webhook"
+// @Success      201      {object}  dto.WebhookCreatedResponse
+// @Failure      400      {object}  jsonutil.Response "Invalid data"
+// @Router       /admin/webhooks [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+	if err := jsonutil.ReadJSON(w, r, &req); err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	if errs := apiutil.ValidateStruct(req); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return
+	}
+
+	hook, err := h.svc.Create(r.Context(), domain.Webhook{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		CreatedBy:   domain.ActorFromContext(r.Context()),
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := dto.WebhookCreatedResponse{WebhookResponse: mapWebhook(hook), Secret: hook.Secret}
+	jsonutil.WriteJSON(w, http.StatusCreated, res, nil, "Webhook created")
+}
+
+// List godoc
+// @Summary      List webhooks
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}  dto.WebhookResponse
+// @Router       /admin/webhooks [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context())
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := make([]dto.WebhookResponse, len(list))
+	for i, hook := range list {
+		res[i] = mapWebhook(hook)
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, res, nil, "Webhooks retrieved")
+}
+
+// Get godoc
+// @Summary      Get a webhook
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  dto.WebhookResponse
+// @Failure      404  {object}  jsonutil.Response "Webhook not found"
+// @Router       /admin/webhooks/{id} [get]
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := ReadIDParam(r)
+
+	hook, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, mapWebhook(hook), nil, "Webhook fetched")
+}
+
+// Update godoc
+// @Summary      Update a webhook
+// @Description  Changes the URL, event types, secret, description or pauses it with active=false. Deliveries of a paused webhook wait until it is active again.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                    true  "Webhook ID"
+// @Param        request  body      dto.UpdateWebhookRequest  true  "Fields to change"
+// @Success      200      {object}  dto.WebhookResponse
+// @Failure      400      {object}  jsonutil.Response "Invalid data"
+// @Failure      404      {object}  jsonutil.Response "Webhook not found"
+// @Router       /admin/webhooks/{id} [patch]
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := ReadIDParam(r)
+
+	var req dto.UpdateWebhookRequest
+	if err := jsonutil.ReadJSON(w, r, &req); err != nil {
+		jsonutil.BadRequestResponse(w, "Bad request", nil)
+		return
+	}
+
+	if errs := apiutil.ValidateStruct(req); errs != nil {
+		jsonutil.BadRequestResponse(w, "Invalid data", errs)
+		return
+	}
+
+	hook, err := h.svc.Update(r.Context(), domain.WebhookUpdate{
+		UUID:        id,
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, mapWebhook(hook), nil, "Webhook updated")
+}
+
+// Delete godoc
+// @Summary      Delete a webhook
+// @Description  Removes the webhook together with its delivery log
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      204  "No Content"
+// @Failure      404  {object}  jsonutil.Response "Webhook not found"
+// @Router       /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := ReadIDParam(r)
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusNoContent, nil, nil, "Webhook deleted")
+}
+
+// Deliveries godoc
+// @Summary      Delivery log of a webhook
+// @Description  Lists deliveries newest first with their attempts and last response
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string  true   "Webhook ID"
+// @Param        status  query     string  false  "Filter by status (pending, succeeded, failed)"
+// @Param        limit   query     int     false  "Page size (default 50, max 500)"
+// @Param        offset  query     int     false  "Rows to skip"
+// @Success      200     {array}   dto.WebhookDeliveryResponse
+// @Failure      400     {object}  jsonutil.Response "Invalid filter"
+// @Failure      404     {object}  jsonutil.Response "Webhook not found"
+// @Router       /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, _ := ReadIDParam(r)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliverySucceeded, domain.DeliveryFailed:
+	default:
+		jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+			{Field: "status", Message: "status must be pending, succeeded or failed"},
+		})
+		return
+	}
+
+	list, err := h.svc.Deliveries(r.Context(), domain.WebhookDeliveryFilter{
+		WebhookID: id,
+		Status:    status,
+		Limit:     ParseQueryInt(r, "limit", 0),
+		Offset:    max(ParseQueryInt(r, "offset", 0), 0),
+	})
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	res := make([]dto.WebhookDeliveryResponse, len(list))
+	for i, d := range list {
+		res[i] = mapDelivery(d)
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, res, nil, "Deliveries retrieved")
+}
+
+// Redeliver godoc
+// @Summary      Redeliver a webhook delivery
+// @Description  Queues the delivery again with a fresh set of attempts, also for deliveries that already succeeded
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Webhook ID"
+// @Param        deliveryID  path      string  true  "Delivery ID"
+// @Success      202         {object}  dto.WebhookDeliveryResponse
+// @Failure      404         {object}  jsonutil.Response "Delivery not found"
+// @Router       /admin/webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, _ := ReadIDParam(r)
+
+	d, err := h.svc.Redeliver(r.Context(), id, chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusAccepted, mapDelivery(d), nil, "Delivery queued")
+}
+
+func mapWebhook(hook *domain.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:          hook.UUID,
+		URL:         hook.URL,
+		EventTypes:  hook.EventTypes,
+		Description: hook.Description,
+		Active:      hook.Active,
+		CreatedBy:   hook.CreatedBy,
+		CreatedAt:   hook.CreatedAt,
+		UpdatedAt:   hook.UpdatedAt,
+	}
+}
+
+func mapDelivery(d *domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+	res := dto.WebhookDeliveryResponse{
+		ID:             d.UUID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == domain.DeliveryPending {
+		res.NextAttemptAt = &d.NextAttemptAt
+	}
+	return res
+}
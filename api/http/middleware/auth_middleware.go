@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
 	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
 )
@@ -42,7 +43,32 @@ func AuthMiddleware(tokenProvider ports.TokenProvider) func(http.Handler) http.H
 
 			//  Inject claims into the context and proceed
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
+			// The actor id travels with the context so services can attribute audit events
+			ctx = domain.WithActor(ctx, claims.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// RequireRole lets the request through only if the authenticated user holds one of the roles.
+// Must run after AuthMiddleware so the claims are in the context.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserContextKey).(domain.UserClaims)
+			if !ok {
+				jsonutil.UnauthorizedResponse(w, "Unauthorized: No claims found")
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			jsonutil.ForbiddenResponse(w, "Insufficient permissions")
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package middleware
+// This one tags requests with a correlation id
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// CorrelationIDHeader lets callers carry their own id through the events a request raises
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// maxCorrelationID keeps a hostile header from bloating every event it ends up in
+const maxCorrelationID = 128
+
+// CorrelationID takes the id from the request header or falls back to the request id,
+// echoes it on the response and puts it in the context for the services.
+// Must run after chi's RequestID middleware.
+func CorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(CorrelationIDHeader)
+		if id == "" || len(id) > maxCorrelationID {
+			id = middleware.GetReqID(r.Context())
+		}
+
+		w.Header().Set(CorrelationIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(domain.WithCorrelationID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,178 @@
+// Package middleware
+// This one replays responses of retried requests carrying an Idempotency-Key
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/AzmainMahtab/go-chi-hex/pkg/jsonutil"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response served from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKey = 255
+)
+
+// Idempotency stores the response of a request carrying an Idempotency-Key for ttl and replays it
+// to retries with the same key. A retry with another method, path, query or body gets 422, one arriving
+// while the first is still running gets 409. Server errors are not stored so they can be retried.
+// Requests without the header pass through untouched.
+func Idempotency(store ports.IdempotencyStore, ttl, lockTTL time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				jsonutil.BadRequestResponse(w, "Invalid data", []jsonutil.ErrorItem{
+					{Field: IdempotencyKeyHeader, Message: "must be at most 255 characters"},
+				})
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, jsonutil.MaxRequestBodySize))
+			if err != nil {
+				jsonutil.BadRequestResponse(w, "Bad request", nil)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := fingerprintRequest(r, body)
+
+			// Keys are scoped to the caller so one client can't replay another's response,
+			// anonymous callers by address since there is nothing else to tell them apart
+			scope := domain.ActorFromContext(r.Context())
+			if scope == "" {
+				scope = clientIP(r)
+			}
+			storeKey := "idempotency:" + scope + ":" + key
+
+			if replayed := replayStored(w, r, store, storeKey, fingerprint); replayed {
+				return
+			}
+
+			unlock, acquired, err := store.Lock(r.Context(), storeKey, lockTTL)
+			if err != nil {
+				storeUnavailable(w, err)
+				return
+			}
+			if !acquired {
+				w.Header().Set("Retry-After", "1")
+				idempotencyError(w, http.StatusConflict, domain.CodeIdempotencyInFlight,
+					"a request with this idempotency key is still being processed")
+				return
+			}
+			defer unlock()
+
+			// The first request may have finished between the lookup and the lock
+			if replayed := replayStored(w, r, store, storeKey, fingerprint); replayed {
+				return
+			}
+
+			// Headers set in front of this middleware (rate limits, correlation id) describe this
+			// request, only what the handler adds is part of the response worth replaying
+			before := w.Header().Clone()
+
+			var captured bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&captured)
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			res := domain.IdempotentResponse{
+				Fingerprint: fingerprint,
+				StatusCode:  status,
+				Header:      addedHeaders(before, w.Header()),
+				Body:        captured.Bytes(),
+				CreatedAt:   time.Now(),
+			}
+			if err := store.Save(r.Context(), storeKey, res, ttl); err != nil {
+				slog.Error("Storing idempotent response failed", "key", key, "error", err)
+			}
+		})
+	}
+}
+
+// replayStored writes the stored response if there is one and reports whether the request was answered
+func replayStored(w http.ResponseWriter, r *http.Request, store ports.IdempotencyStore, key, fingerprint string) bool {
+	stored, err := store.Get(r.Context(), key)
+	if err != nil {
+		storeUnavailable(w, err)
+		return true
+	}
+	if stored == nil {
+		return false
+	}
+
+	if stored.Fingerprint != fingerprint {
+		idempotencyError(w, http.StatusUnprocessableEntity, domain.CodeIdempotencyKeyReused,
+			"this idempotency key was already used for a different request")
+		return true
+	}
+
+	// Headers already set belong to the retry itself and win over the stored ones
+	for name, values := range stored.Header {
+		if _, live := w.Header()[name]; !live {
+			w.Header()[name] = values
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	w.Write(stored.Body)
+	return true
+}
+
+// addedHeaders returns the headers of after that are new or changed since before
+func addedHeaders(before, after http.Header) http.Header {
+	added := http.Header{}
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			added[name] = slices.Clone(values)
+		}
+	}
+	return added
+}
+
+// fingerprintRequest hashes what makes two requests the same operation. The query is
+// re-encoded sorted, so the same parameters in another order are the same request.
+func fingerprintRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"?"+r.URL.Query().Encode()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyError writes the same body HandleError does for the code, handlers can't be imported from here
+func idempotencyError(w http.ResponseWriter, status int, code domain.ErrorCode, message string) {
+	jsonutil.ErrorResponse(w, status, message, []jsonutil.ErrorItem{
+		{Code: string(code), Field: IdempotencyKeyHeader, Message: message},
+	})
+}
+
+func storeUnavailable(w http.ResponseWriter, err error) {
+	slog.Error("Idempotency store unavailable", "error", err)
+	jsonutil.ErrorResponse(w, http.StatusServiceUnavailable, "idempotency store unavailable", nil)
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/memory"
+)
+
+// counting answers with how many times it ran, so a replay shows the first run's number
+func counting() (http.Handler, *int) {
+	runs := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "run %d", runs)
+	}), &runs
+}
+
+func send(h http.Handler, target, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"a":1}`))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func newIdempotent(next http.Handler) http.Handler {
+	store := memory.NewIdempotencyStore(memory.NewCache(0))
+	return Idempotency(store, time.Hour, time.Minute)(next)
+}
+
+func TestIdempotencyScopesAnonymousKeysByClient(t *testing.T) {
+	next, runs := counting()
+	h := newIdempotent(next)
+
+	first := send(h, "/auth/register", "10.0.0.1:4000")
+	retry := send(h, "/auth/register", "10.0.0.1:4001")
+	if retry.Body.String() != first.Body.String() || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry from the same client was not replayed: %q", retry.Body.String())
+	}
+
+	other := send(h, "/auth/register", "10.0.0.2:4000")
+	if other.Header().Get(IdempotentReplayedHeader) != "" || other.Body.String() != "run 2" {
+		t.Fatalf("another client got the first one's response: %q", other.Body.String())
+	}
+	if *runs != 2 {
+		t.Fatalf("handler ran %d times", *runs)
+	}
+}
+
+func TestIdempotencyFingerprintsTheQuery(t *testing.T) {
+	next, _ := counting()
+	h := newIdempotent(next)
+
+	send(h, "/users/import?dry_run=true&format=csv", "10.0.0.1:4000")
+
+	reordered := send(h, "/users/import?format=csv&dry_run=true", "10.0.0.1:4000")
+	if reordered.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("same query in another order was not replayed: %d", reordered.Code)
+	}
+
+	changed := send(h, "/users/import?dry_run=false&format=csv", "10.0.0.1:4000")
+	if changed.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("key reused with another query: %d, want 422", changed.Code)
+	}
+}
+
+func TestIdempotencyReplayKeepsTheRetrysOwnHeaders(t *testing.T) {
+	next, _ := counting()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/users/1")
+		next.ServeHTTP(w, r)
+	})
+	idempotent := newIdempotent(handler)
+
+	// Stands in for the rate limiter and correlation id in front of the store
+	remaining := 10
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining--
+		w.Header().Set("RateLimit-Remaining", fmt.Sprint(remaining))
+		w.Header().Set(CorrelationIDHeader, fmt.Sprintf("req-%d", remaining))
+		idempotent.ServeHTTP(w, r)
+	})
+
+	send(h, "/auth/register", "10.0.0.1:4000")
+	retry := send(h, "/auth/register", "10.0.0.1:4000")
+
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("retry was not replayed")
+	}
+	if got := retry.Header().Get("RateLimit-Remaining"); got != "8" {
+		t.Fatalf("RateLimit-Remaining %q, want the retry's own 8", got)
+	}
+	if got := retry.Header().Get(CorrelationIDHeader); got != "req-8" {
+		t.Fatalf("%s %q, want the retry's own req-8", CorrelationIDHeader, got)
+	}
+	if got := retry.Header().Get("Location"); got != "/users/1" {
+		t.Fatalf("Location %q, the handler's headers should replay", got)
+	}
+}
@@ -0,0 +1,27 @@
+// Package middleware
+// This one decides whether a request may read from a replica
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// ReadConsistencyHeader set to "strong" makes a read see everything written before it,
+// a client sends it on the reads that follow its own writes
+const ReadConsistencyHeader = "X-Read-Consistency"
+
+// ReadConsistency sends every read of a write request to the primary, as well as the reads of
+// requests asking for strong consistency. The rest may be answered by a lagging replica.
+func ReadConsistency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions,
+			strings.EqualFold(r.Header.Get(ReadConsistencyHeader), "strong"):
+			r = r.WithContext(domain.WithPrimaryReads(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
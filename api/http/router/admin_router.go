@@ -0,0 +1,57 @@
+// Package routes
+// this contains the admin only routes
+package routes
+
+import (
+	"net/http"
+
+	"github.com/AzmainMahtab/go-chi-hex/api/http/handlers"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/middleware"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+func adminRouter(uh *handlers.UserHandler, adh *handlers.AuditHandler, wh *handlers.WebhookHandler, limit func(http.Handler) http.Handler, tokenProvider ports.TokenProvider) http.Handler {
+	r := chi.NewRouter()
+
+	// Every admin route needs a valid token carrying the admin role
+	r.Use(middleware.AuthMiddleware(tokenProvider))
+	r.Use(middleware.RequireRole("admin"))
+	r.Use(limit)
+
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/export", uh.Export)           // GET /admin/users/export
+		r.Post("/trash/purge", uh.PurgeTrash) // POST /admin/users/trash/purge
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Post("/suspend", uh.Suspend)        // POST /admin/users/{id}/suspend
+			r.Post("/unsuspend", uh.Unsuspend)    // POST /admin/users/{id}/unsuspend
+			r.Get("/suspensions", uh.Suspensions) // GET /admin/users/{id}/suspensions
+		})
+	})
+
+	r.Route("/audit", func(r chi.Router) {
+		r.Get("/", adh.List)               // GET /admin/audit
+		r.Get("/verify", adh.Verify)       // GET /admin/audit/verify
+		r.Get("/ingestion", adh.Ingestion) // GET /admin/audit/ingestion
+		r.Get("/stream", adh.Stream)       // GET /admin/audit/stream (SSE)
+
+		r.Get("/dlq", adh.DeadLetters)                    // GET /admin/audit/dlq
+		r.Post("/dlq/{seq}/replay", adh.ReplayDeadLetter) // POST /admin/audit/dlq/{seq}/replay
+	})
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Post("/", wh.Create) // POST /admin/webhooks
+		r.Get("/", wh.List)    // GET /admin/webhooks
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", wh.Get)                                         // GET /admin/webhooks/{id}
+			r.Patch("/", wh.Update)                                    // PATCH /admin/webhooks/{id}
+			r.Delete("/", wh.Delete)                                   // DELETE /admin/webhooks/{id}
+			r.Get("/deliveries", wh.Deliveries)                        // GET /admin/webhooks/{id}/deliveries
+			r.Post("/deliveries/{deliveryID}/redeliver", wh.Redeliver) // POST /admin/webhooks/{id}/deliveries/{deliveryID}/redeliver
+		})
+	})
+
+	return r
+}
@@ -11,11 +11,14 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func authRouter(ah *handlers.AuthHandler, tokenProvider ports.TokenProvider) http.Handler {
+func authRouter(ah *handlers.AuthHandler, idempotency, limit func(http.Handler) http.Handler, tokenProvider ports.TokenProvider) http.Handler {
 	r := chi.NewRouter()
 
+	// Public routes are a brute force target, the whole group is limited
+	r.Use(limit)
+
 	//  PUBLIC ROUTES No Middlewar
-	r.Post("/register", ah.Register)
+	r.With(idempotency).Post("/register", ah.Register)
 	r.Post("/login", ah.Login)
 	r.Post("/rotate", ah.Rotate)
 
@@ -19,6 +19,16 @@ type RouterDependencies struct {
 	HealthH *handlers.HealthHandler
 	UserH   *handlers.UserHandler
 	AuthH   *handlers.AuthHandler
+	AuditH  *handlers.AuditHandler
+	HookH   *handlers.WebhookHandler
+
+	// Idempotency replays retried POSTs that carry an Idempotency-Key
+	Idempotency func(http.Handler) http.Handler
+
+	// Rate limits of the route groups
+	AuthLimit  func(http.Handler) http.Handler
+	UserLimit  func(http.Handler) http.Handler
+	AdminLimit func(http.Handler) http.Handler
 }
 
 func NewRouter(deps RouterDependencies, tokenProvider ports.TokenProvider) http.Handler {
@@ -27,14 +37,17 @@ func NewRouter(deps RouterDependencies, tokenProvider ports.TokenProvider) http.
 	// chi middleware stack
 	// r.Use(chiMiddleware.Logger)
 	r.Use(chiMiddleware.RequestID)
+	r.Use(middleware.CorrelationID)
+	r.Use(middleware.ReadConsistency)
 	r.Use(middleware.StructuredLogger)
 	r.Use(chiMiddleware.Recoverer)
 
 	// Main router group
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Get("/health", deps.HealthH.HealthCheck)
-		r.Mount("/user", userRouter(deps.UserH, tokenProvider))
-		r.Mount("/auth", authRouter(deps.AuthH, tokenProvider))
+		r.Mount("/user", userRouter(deps.UserH, deps.Idempotency, deps.UserLimit, tokenProvider))
+		r.Mount("/auth", authRouter(deps.AuthH, deps.Idempotency, deps.AuthLimit, tokenProvider))
+		r.Mount("/admin", adminRouter(deps.UserH, deps.AuditH, deps.HookH, deps.AdminLimit, tokenProvider))
 	})
 
 	// --- Static Handler for /docs/* ---
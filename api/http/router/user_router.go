@@ -11,19 +11,21 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func userRouter(uh *handlers.UserHandler, tokenProvider ports.TokenProvider) http.Handler {
+func userRouter(uh *handlers.UserHandler, idempotency, limit func(http.Handler) http.Handler, tokenProvider ports.TokenProvider) http.Handler {
 	r := chi.NewRouter()
 
 	// General User Routes
-	r.Post("/", uh.CreateUser)                                         // POST /user
-	r.With(middleware.AuthMiddleware(tokenProvider)).Get("/", uh.List) // GET /user
+	// The limit runs after auth where there is one so it counts per user, not per IP
+	r.With(limit, idempotency).Post("/", uh.CreateUser)                       // POST /user
+	r.With(middleware.AuthMiddleware(tokenProvider), limit).Get("/", uh.List) // GET /user
 
 	// Special route for trashed users
-	r.Get("/trash", uh.GetTrashed) // GET /user/trash
+	r.With(limit).Get("/trash", uh.GetTrashed) // GET /user/trash
 
 	// Specific User ID Routes
 	r.Route("/{id}", func(r chi.Router) {
 		r.Use(middleware.AuthMiddleware(tokenProvider))
+		r.Use(limit)
 		r.Get("/", uh.GetByID)          // GET /user/{id}
 		r.Patch("/", uh.Update)         // PATCH /user/{id}
 		r.Delete("/", uh.Remove)        // DELETE /user/{id} (Soft Delete)
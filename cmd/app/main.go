@@ -15,6 +15,7 @@ package main
 import (
 	// ... imports for context, log, net/http, os, signal, syscall, time ...
 	"context"
+	"database/sql"
 	"log"
 	"log/slog"
 	"net/http"
@@ -24,14 +25,26 @@ import (
 	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/api/http/handlers"
+	"github.com/AzmainMahtab/go-chi-hex/api/http/middleware"
 	routes "github.com/AzmainMahtab/go-chi-hex/api/http/router"
 	"github.com/AzmainMahtab/go-chi-hex/internal/config"
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/cache"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/localfs"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/memory"
 	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/nats"
 	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/postgres"
 	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/redis"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/sqlite"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/webhook"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
 	"github.com/AzmainMahtab/go-chi-hex/internal/secure"
+	"github.com/AzmainMahtab/go-chi-hex/internal/services/audit"
 	"github.com/AzmainMahtab/go-chi-hex/internal/services/auth"
 	"github.com/AzmainMahtab/go-chi-hex/internal/services/users"
+	"github.com/AzmainMahtab/go-chi-hex/internal/services/webhooks"
+	"github.com/AzmainMahtab/go-chi-hex/internal/supervisor"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
@@ -49,39 +62,87 @@ func main() {
 		Password: cfg.DB.Password,
 		DBName:   cfg.DB.DBName,
 		PoolSize: cfg.DB.PoolSize,
+
+		ReplicaDSNs: cfg.DB.ReplicaDSNs,
+
+		MinConns:           cfg.DB.MinConns,
+		HealthCheckPeriod:  cfg.DB.HealthCheckPeriod,
+		StatementCacheMode: cfg.DB.StatementCacheMode,
 	}
 
 	// connect to the Database
-	db, err := postgres.ConnectDB(dbConfig)
-	if err != nil {
-		log.Fatalf("FATAL: Database connection failed: %v", err)
+	// With the pgx driver database/sql runs on top of the pool, so there is one set of connections
+	var (
+		db   *sql.DB
+		pool *pgxpool.Pool
+	)
+	switch cfg.DB.Driver {
+	case "sqlite":
+		db, err = sqlite.Open(cfg.DB.SQLitePath)
+		if err != nil {
+			log.Fatalf("FATAL: SQLite setup failed: %v", err)
+		}
+	case "pgx":
+		pool, err = postgres.ConnectPool(dbConfig)
+		if err != nil {
+			log.Fatalf("FATAL: Database connection failed: %v", err)
+		}
+		defer pool.Close()
+		db = postgres.PoolDB(pool)
+	default:
+		db, err = postgres.ConnectDB(dbConfig)
+		if err != nil {
+			log.Fatalf("FATAL: Database connection failed: %v", err)
+		}
 	}
 	defer db.Close() // Ensure the connection is closed on exit
 
-	//Redis Setup and connect
-	redisConfig := redis.RedisConfig{
-		Host:     cfg.Redis.Host,
-		Port:     cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.RedisDB,
-	}
-
-	redisClient, err := redis.NewRedisClient(redisConfig)
+	replicas, err := postgres.ConnectReplicas(dbConfig)
 	if err != nil {
-		log.Fatalf("FATAL: Redis connection failed: %v", err)
+		log.Fatalf("FATAL: Read replica setup failed: %v", err)
 	}
+	for _, replica := range replicas {
+		defer replica.Close()
+	}
+	readRouter := postgres.NewReadRouter(db, replicas)
 
-	defer redisClient.Close()
-
-	// NATS SETUP
+	// CACHE SETUP
+	// Redis backs the cache, rate limits and idempotency keys, the memory driver keeps them in process
+	var (
+		cacheRepo        ports.CacheRepo
+		rateLimiter      ports.RateLimiter
+		idempotencyStore ports.IdempotencyStore
+		cacheJanitor     ports.BackgroundWorker
+		redisLocker      ports.Locker
+	)
+	switch cfg.Cache.Driver {
+	case "memory":
+		// Revocations are pinned, evicting one would make a revoked refresh token valid again
+		memCache := memory.NewCache(cfg.Cache.MemoryMaxKeys, auth.RevokedRefreshPrefix)
+		cacheRepo = memCache
+		rateLimiter = memory.NewRateLimiter()
+		idempotencyStore = memory.NewIdempotencyStore(memCache)
+		cacheJanitor = memCache.Janitor(cfg.Cache.JanitorInterval)
+		log.Println("Cache driver: memory, redis is not used")
+	default:
+		redisConfig := redis.RedisConfig{
+			Host:     cfg.Redis.Host,
+			Port:     cfg.Redis.Port,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.RedisDB,
+		}
 
-	nc, err := nats.NewNATS(cfg.NATS.URL)
-	if err != nil {
-		slog.Error("NATS connection failed", "error", err)
-	}
+		redisClient, err := redis.NewRedisClient(redisConfig)
+		if err != nil {
+			log.Fatalf("FATAL: Redis connection failed: %v", err)
+		}
+		defer redisClient.Close()
 
-	if err := nats.InitEventStream(nc, "AUDIT_STREAM", []string{"audit.event"}); err != nil {
-		slog.Error("NATS stream initialization failed", "error", err)
+		cacheRepo = redis.NewRedisAdapter(redisClient)
+		// Counted in redis so the limits hold across replicas, per process while redis is down
+		rateLimiter = redis.NewRateLimiter(redisClient, memory.NewRateLimiter())
+		idempotencyStore = redis.NewIdempotencyStore(redisClient)
+		redisLocker = redis.NewLocker(redisClient)
 	}
 
 	//JWT SETUP
@@ -102,28 +163,196 @@ func main() {
 	bcryptHasher := secure.NewBcryptHasher(bcryptStrength.Cost)
 
 	// REPOSITORY SETUP
-	userRepo := postgres.NewUserRepo(db)
-	redisRepo := redis.NewRedisAdapter(redisClient)
-	auditRepo := postgres.NewAuditRepo(db)
+	// Services group writes and their outbox events into one transaction through txManager
+	var (
+		txManager      ports.TxManager
+		userRepo       ports.UserRepository
+		suspensionRepo ports.SuspensionRepository
+		auditRepo      ports.AuditRepository
+		auditChain     ports.AuditChainRepository
+		webhookRepo    ports.WebhookRepository
+		// Archiving the audit log stays on database/sql with either postgres driver, sqlite has no partitions to archive
+		auditArchiveRepo *postgres.AuditRepo
+	)
+	switch cfg.DB.Driver {
+	case "sqlite":
+		sqliteAuditRepo := sqlite.NewAuditRepo(db)
+		txManager = sqlite.NewTxManager(db)
+		userRepo, auditRepo, auditChain = sqlite.NewUserRepo(db), sqliteAuditRepo, sqliteAuditRepo
+		suspensionRepo, webhookRepo = sqlite.NewSuspensionRepo(db), sqlite.NewWebhookRepo(db)
+	default:
+		txManager = postgres.NewTxManager(db, postgres.TxRetryPolicy{
+			MaxRetries: cfg.DB.TxMaxRetries,
+			BaseDelay:  cfg.DB.TxRetryBaseDelay,
+			MaxDelay:   cfg.DB.TxRetryMaxDelay,
+		})
+		auditArchiveRepo = postgres.NewAuditRepo(db)
+		suspensionRepo, webhookRepo = postgres.NewSuspensionRepo(db), postgres.NewWebhookRepo(db)
+
+		if cfg.DB.Driver == "pgx" {
+			pgxAuditRepo := postgres.NewPgxAuditRepo(pool)
+			userRepo, auditRepo, auditChain = postgres.NewPgxUserRepo(pool), pgxAuditRepo, pgxAuditRepo
+		} else {
+			userRepo, auditRepo, auditChain = postgres.NewUserRepo(db, readRouter), auditArchiveRepo, auditArchiveRepo
+		}
+	}
+	if cfg.Cache.UserTTL > 0 {
+		userRepo = cache.NewUserRepo(userRepo, cacheRepo, txManager, cfg.Cache.UserTTL)
+	}
+
+	// Jobs that must run on a single replica take their locks here
+	var jobLocker ports.Locker
+	switch cfg.Jobs.LockDriver {
+	case "redis":
+		jobLocker = redisLocker
+	case "memory":
+		jobLocker = memory.NewLocker()
+	default:
+		jobLocker = postgres.NewAdvisoryLocker(db)
+	}
+
+	// EVENT BUS SETUP
+	// The audit log is one subscriber of the bus, more subscribe next to it under their own name
+	var (
+		eventBus       ports.EventBus
+		eventPublisher ports.EventPublisher
+		deadLetters    ports.DeadLetterQueue
+		auditIngest    ports.AuditIngestMonitor
+		auditStream    ports.AuditStream
+		eventWorkers   = map[string]ports.BackgroundWorker{}
+	)
+
+	switch cfg.Events.Driver {
+	case "memory":
+		// No broker, events go straight from the services to the subscribers in this process
+		var (
+			bus   *memory.EventBus
+			queue *memory.AuditQueue
+		)
+		if cfg.Events.MemorySync {
+			bus = memory.NewSyncEventBus()
+			queue = memory.NewSyncAuditQueue(auditRepo, bus.DeadLetters())
+		} else {
+			bus = memory.NewEventBus(cfg.Events.MemoryBuffer)
+			queue = memory.NewAuditQueue(auditRepo, bus.DeadLetters(), cfg.Events.MemoryBuffer, cfg.NATS.BatchSize)
+		}
+		// Events raised in a transaction wait for its commit, the outbox gives the nats driver the same
+		eventBus, eventPublisher, deadLetters, auditIngest = bus, memory.NewTxPublisher(bus, txManager), bus.DeadLetters(), queue
+		eventWorkers["audit-events"] = bus.Subscribe(memory.AuditConsumer, []string{">"}, queue.Handle)
+		eventWorkers["audit-worker"] = queue
+		slog.Info("Using in-memory event driver")
 
-	//Audit stream setup
-	auditWorker := nats.NewAuditWorker(nc, auditRepo)
-	auditPublisher := nats.NewNatsEventPublisher(nc)
+	default:
+		// NATS is optional at startup, events wait in the outbox until it is reachable
+		nc, err := nats.NewNATS(cfg.NATS.URL)
+		if err != nil {
+			log.Fatalf("FATAL: NATS setup failed: %v", err)
+		}
 
-	auditWorker.Start(context.Background())
+		if err := nats.EnsureStreams(nc); err != nil {
+			slog.Warn("NATS stream initialization deferred", "error", err)
+		}
+
+		// Events are written to the outbox and relayed to JetStream, so NATS being down never loses them
+		outboxRepo := postgres.NewOutboxRepo(db)
+		auditWorker := nats.NewAuditWorker(nc, auditRepo, cfg.NATS.MaxDeliver, cfg.NATS.BatchSize, cfg.NATS.Concurrency)
+
+		// Services publish through the outbox, the bus only carries subscriptions
+		eventBus = nats.NewEventBus(nc, cfg.NATS.MaxDeliver)
+		eventPublisher, deadLetters, auditIngest = outboxRepo, nats.NewDeadLetterQueue(nc), auditWorker
+		auditStream = nats.NewAuditStreamReader(nc)
+		// One relay at a time keeps events in outbox order
+		eventWorkers["outbox-relay"] = supervisor.Leader(jobLocker, "leader:outbox-relay", cfg.Jobs.LeaderLockTTL,
+			nats.NewOutboxRelay(nc, outboxRepo, cfg.Jobs.OutboxPollInterval, cfg.Jobs.OutboxBatchSize, cfg.Jobs.OutboxRetention))
+		eventWorkers["audit-worker"] = auditWorker
+	}
 
 	// SERVICE SETUP
-	userService := users.NewUserService(userRepo, bcryptHasher)
-	authService := auth.NewAuthService(userRepo, jwtAdapter, redisRepo, bcryptHasher, auditPublisher)
+	userService := users.NewUserService(userRepo, suspensionRepo, bcryptHasher, eventPublisher, txManager)
+	authService := auth.NewAuthService(userRepo, suspensionRepo, jwtAdapter, cacheRepo, bcryptHasher, eventPublisher, txManager)
+
+	// Reinstates users once their suspension runs out
+	// Two replicas sweeping at once would lift the same suspension twice
+	suspensionScheduler := supervisor.Leader(jobLocker, "leader:suspension-scheduler", cfg.Jobs.LeaderLockTTL,
+		users.NewSuspensionScheduler(userService, cfg.Jobs.SuspensionSweepInterval))
+
+	// Hard deletes users that stayed in the trash past the retention period
+	trashPurger := users.NewTrashPurger(userService, jobLocker, cfg.Jobs.TrashPurgeInterval, cfg.Jobs.TrashRetention, cfg.Jobs.TrashPurgeDryRun)
+
+	// Audit checkpoints are signed with the same ECDSA key pair as the JWTs
+	checkpointSigner := secure.NewECDSASigner(privKey, pubKey)
+	auditService := audit.NewAuditService(auditRepo, auditChain, checkpointSigner, deadLetters, auditIngest, auditStream)
+
+	auditCheckpointer := audit.NewCheckpointer(auditService, jobLocker, cfg.Jobs.AuditCheckpointInterval)
+
+	// Expired audit partitions are exported to compressed files before they are dropped
+	var auditArchiveJob ports.BackgroundWorker
+	if auditArchiveRepo != nil {
+		archiveStorage, err := localfs.NewStorage(cfg.Jobs.AuditArchiveDir)
+		if err != nil {
+			log.Fatalf("FATAL: Audit archive storage setup failed: %v", err)
+		}
+		auditArchiver := audit.NewArchiver(auditArchiveRepo, archiveStorage, cfg.Jobs.AuditRetentionMonths)
+		auditArchiveJob = audit.NewArchiveJob(auditArchiver, jobLocker, cfg.Jobs.AuditArchiveInterval)
+	}
+
+	// Partner webhooks subscribe to the bus next to the audit log, the dispatcher sends and retries
+	webhookService := webhooks.NewWebhookService(webhookRepo, webhook.NewSender(cfg.Webhooks.Timeout), cfg.Webhooks.MaxAttempts, cfg.Webhooks.BatchSize)
+	eventWorkers["webhook-events"] = eventBus.Subscribe("webhooks", []string{">"}, webhookService.HandleEvent)
+	webhookDispatcher := webhooks.NewDispatcher(webhookService, cfg.Webhooks.PollInterval, cfg.Webhooks.BatchSize)
+
+	// WORKER SETUP
+	// Drained in this order on shutdown: jobs that emit events first, then the outbox relay
+	// forwards what they wrote, the subscribers handle whatever is left in the stream and
+	// the webhook dispatcher finishes the deliveries it claimed
+	workers := supervisor.New()
+	workers.Add("suspension-scheduler", suspensionScheduler)
+	workers.Add("trash-purger", trashPurger)
+	workers.Add("audit-checkpointer", auditCheckpointer)
+	if auditArchiveJob != nil {
+		workers.Add("audit-archiver", auditArchiveJob)
+	}
+	for _, name := range []string{"outbox-relay", "audit-events", "audit-worker", "webhook-events"} {
+		if w, ok := eventWorkers[name]; ok {
+			workers.Add(name, w)
+		}
+	}
+	workers.Add("webhook-dispatcher", webhookDispatcher)
+	if cacheJanitor != nil {
+		workers.Add("cache-janitor", cacheJanitor)
+	}
+	if len(replicas) > 0 {
+		workers.Add("replica-health", readRouter.HealthCheck(cfg.DB.ReplicaCheckInterval))
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	workers.Start(workerCtx)
+
 	// HANDLER AND ROUTER SETUP
-	healthHandler := handlers.NewHealthHandleer()
+	healthHandler := handlers.NewHealthHandleer(workers)
 	userHandler := handlers.NewUserHandler(userService)
 	authHandler := handlers.NewAuthHandler(authService)
+	// Shutdown waits for every handler to return, audit streams only end when told to
+	streams, endStreams := context.WithCancel(context.Background())
+	auditHandler := handlers.NewAuditHandler(auditService, streams.Done())
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	rateLimit := func(group string, rule config.RateLimitRule) func(http.Handler) http.Handler {
+		limit := domain.RateLimit{Limit: rule.Limit, Period: rule.Period}
+		return middleware.RateLimit(rateLimiter, group, limit, middleware.RateLimitKey(rule.KeyBy))
+	}
 
 	deps := routes.RouterDependencies{
-		HealthH: healthHandler,
-		UserH:   userHandler,
-		AuthH:   authHandler,
+		HealthH:     healthHandler,
+		UserH:       userHandler,
+		AuthH:       authHandler,
+		AuditH:      auditHandler,
+		HookH:       webhookHandler,
+		Idempotency: middleware.Idempotency(idempotencyStore, cfg.Idempotency.TTL, cfg.Idempotency.LockTTL),
+		AuthLimit:   rateLimit("auth", cfg.RateLimits.Auth),
+		UserLimit:   rateLimit("user", cfg.RateLimits.User),
+		AdminLimit:  rateLimit("admin", cfg.RateLimits.Admin),
 	}
 	router := routes.NewRouter(deps, jwtAdapter)
 
@@ -135,6 +364,7 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	server.RegisterOnShutdown(endStreams)
 
 	// Start the server in a non-blocking goroutine
 	go func() {
@@ -149,12 +379,22 @@ func main() {
 
 	<-quit
 	log.Println("Shutting down server...")
+	workers.MarkDraining()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Workers are drained even if the server did not shut down cleanly
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("FATAL: Server forced to shutdown: %v", err)
+		log.Printf("ERROR: Server forced to shutdown: %v", err)
+	}
+
+	log.Println("Draining background workers...")
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.Jobs.WorkerDrainTimeout)
+	defer cancelDrain()
+
+	if err := workers.Shutdown(drainCtx); err != nil {
+		log.Printf("ERROR: %v", err)
 	}
 	log.Println("Server exiting gracefully.")
 }
@@ -0,0 +1,78 @@
+// Command auditimport loads an archived audit partition back into the database for an investigation.
+//
+//	go run ./cmd/auditimport -manifest audit/audit_log_p20250101/manifest.json
+//
+// The manifest key is relative to AUDIT_ARCHIVE_DIR, or use -list to see what is archived.
+// Rows land in audit_archive_import, apart from the live hash chain, and loading twice is harmless.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/config"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/localfs"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/postgres"
+	"github.com/AzmainMahtab/go-chi-hex/internal/services/audit"
+)
+
+func main() {
+	manifestFlag := flag.String("manifest", "", "manifest key of the archive to import")
+	listFlag := flag.Bool("list", false, "list archived manifests and exit")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to load configuration: %v", err)
+	}
+
+	store, err := localfs.NewStorage(cfg.Jobs.AuditArchiveDir)
+	if err != nil {
+		log.Fatalf("FATAL: Archive storage setup failed: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if *listFlag {
+		keys, err := store.List(ctx, "audit/")
+		if err != nil {
+			log.Fatalf("FATAL: Listing archives failed: %v", err)
+		}
+		for _, k := range keys {
+			if strings.HasSuffix(k, "/manifest.json") {
+				fmt.Println(k)
+			}
+		}
+		return
+	}
+
+	if *manifestFlag == "" {
+		log.Fatal("FATAL: -manifest is required (see -list)")
+	}
+
+	db, err := postgres.ConnectDB(postgres.Config{
+		Host:     cfg.DB.Host,
+		Port:     cfg.DB.Port,
+		User:     cfg.DB.User,
+		Password: cfg.DB.Password,
+		DBName:   cfg.DB.DBName,
+		PoolSize: 2,
+	})
+	if err != nil {
+		log.Fatalf("FATAL: Database connection failed: %v", err)
+	}
+	defer db.Close()
+
+	archiver := audit.NewArchiver(postgres.NewAuditRepo(db), store, cfg.Jobs.AuditRetentionMonths)
+
+	m, imported, err := archiver.Import(ctx, *manifestFlag)
+	if err != nil {
+		log.Fatalf("FATAL: Import failed: %v", err)
+	}
+
+	fmt.Printf("Imported %d of %d rows from %s (%s to %s) into audit_archive_import\n",
+		imported, m.Rows, m.Partition, m.From.Format("2006-01-02"), m.To.Format("2006-01-02"))
+}
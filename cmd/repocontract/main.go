@@ -0,0 +1,133 @@
+// Command repocontract runs the shared repository contract against one backend.
+//
+//	go run ./cmd/repocontract -driver sqlite
+//	go run ./cmd/repocontract -driver sqlx
+//
+// sqlite runs on a fresh temporary file unless -sqlite-path is given. sqlx and pgx use the
+// database configured in .env, the cases leave rows behind so point it at a throwaway one.
+// It exits with status 1 when any case fails, CI runs it on both postgres drivers (.github/workflows/ci.yml).
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/config"
+	"github.com/AzmainMahtab/go-chi-hex/internal/contract"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/postgres"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/sqlite"
+)
+
+func main() {
+	driverFlag := flag.String("driver", "sqlite", "backend to check: sqlite, sqlx or pgx")
+	pathFlag := flag.String("sqlite-path", "", "sqlite database file (default a temporary one)")
+	flag.Parse()
+
+	repos, closeDB, err := open(*driverFlag, *pathFlag)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	defer closeDB()
+
+	failed := 0
+	for _, res := range contract.Run(context.Background(), repos) {
+		if res.Err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", res.Name, res.Err)
+			continue
+		}
+		fmt.Printf("ok    %s\n", res.Name)
+	}
+
+	if failed > 0 {
+		fmt.Printf("%s: %d of %d cases failed\n", *driverFlag, failed, len(contract.Cases))
+		closeDB()
+		os.Exit(1)
+	}
+	fmt.Printf("%s: all %d cases passed\n", *driverFlag, len(contract.Cases))
+}
+
+func open(driver, path string) (contract.Repos, func(), error) {
+	if driver == "sqlite" {
+		cleanup := func() {}
+		if path == "" {
+			dir, err := os.MkdirTemp("", "repocontract")
+			if err != nil {
+				return contract.Repos{}, nil, err
+			}
+			path = filepath.Join(dir, "contract.db")
+			cleanup = func() { os.RemoveAll(dir) }
+		}
+
+		db, err := sqlite.Open(path)
+		if err != nil {
+			cleanup()
+			return contract.Repos{}, nil, err
+		}
+		audit := sqlite.NewAuditRepo(db)
+		repos := contract.Repos{
+			Users:       sqlite.NewUserRepo(db),
+			Suspensions: sqlite.NewSuspensionRepo(db),
+			Audit:       audit,
+			AuditChain:  audit,
+			Tx:          sqlite.NewTxManager(db),
+		}
+		return repos, sync.OnceFunc(func() { db.Close(); cleanup() }), nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return contract.Repos{}, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	dbConfig := postgres.Config{
+		Host:               cfg.DB.Host,
+		Port:               cfg.DB.Port,
+		User:               cfg.DB.User,
+		Password:           cfg.DB.Password,
+		DBName:             cfg.DB.DBName,
+		PoolSize:           4,
+		MinConns:           1,
+		HealthCheckPeriod:  cfg.DB.HealthCheckPeriod,
+		StatementCacheMode: cfg.DB.StatementCacheMode,
+	}
+
+	var db *sql.DB
+	repos := contract.Repos{}
+	switch driver {
+	case "pgx":
+		pool, err := postgres.ConnectPool(dbConfig)
+		if err != nil {
+			return contract.Repos{}, nil, err
+		}
+		db = postgres.PoolDB(pool)
+		audit := postgres.NewPgxAuditRepo(pool)
+		repos.Users, repos.Audit, repos.AuditChain = postgres.NewPgxUserRepo(pool), audit, audit
+		return withShared(repos, db, cfg), sync.OnceFunc(func() { db.Close(); pool.Close() }), nil
+	case "sqlx":
+		if db, err = postgres.ConnectDB(dbConfig); err != nil {
+			return contract.Repos{}, nil, err
+		}
+		audit := postgres.NewAuditRepo(db)
+		repos.Users, repos.Audit, repos.AuditChain = postgres.NewUserRepo(db, postgres.NewReadRouter(db, nil)), audit, audit
+		return withShared(repos, db, cfg), sync.OnceFunc(func() { db.Close() }), nil
+	default:
+		return contract.Repos{}, nil, fmt.Errorf("unknown -driver %q: use sqlite, sqlx or pgx", driver)
+	}
+}
+
+// withShared adds the postgres adapters both postgres drivers run on database/sql
+func withShared(repos contract.Repos, db *sql.DB, cfg *config.Config) contract.Repos {
+	repos.Suspensions = postgres.NewSuspensionRepo(db)
+	repos.Tx = postgres.NewTxManager(db, postgres.TxRetryPolicy{
+		MaxRetries: cfg.DB.TxMaxRetries,
+		BaseDelay:  cfg.DB.TxRetryBaseDelay,
+		MaxDelay:   cfg.DB.TxRetryMaxDelay,
+	})
+	return repos
+}
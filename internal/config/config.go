@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -22,6 +23,22 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	PoolSize int
+	// Transactions that hit a serialization failure or deadlock are retried this many times
+	TxMaxRetries     int
+	TxRetryBaseDelay time.Duration
+	TxRetryMaxDelay  time.Duration
+	// Reads of the user repository go to these replicas, health checked every ReplicaCheckInterval
+	ReplicaDSNs          []string
+	ReplicaCheckInterval time.Duration
+
+	// Driver "sqlx" runs every repository on database/sql, "pgx" moves the user and audit
+	// repositories onto a pgxpool the rest shares through database/sql. "sqlite" keeps
+	// everything in the file at SQLitePath and needs no postgres at all.
+	Driver             string
+	SQLitePath         string
+	MinConns           int
+	HealthCheckPeriod  time.Duration
+	StatementCacheMode string
 }
 
 type RedisConfig struct {
@@ -40,15 +57,88 @@ type JWTConfig struct {
 }
 
 type NATSConfig struct {
-	URL string
+	URL         string
+	MaxDeliver  int
+	BatchSize   int
+	Concurrency int
+}
+
+// EventsConfig picks how audit events travel from the services to the audit log.
+// "nats" goes through the outbox and JetStream, "memory" stays in process and needs no broker.
+type EventsConfig struct {
+	Driver       string
+	MemoryBuffer int
+	MemorySync   bool
+}
+
+// WebhookConfig tunes outgoing webhook deliveries
+type WebhookConfig struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// IdempotencyConfig is how long responses are kept for Idempotency-Key replays
+// and how long a request holds its key while it runs
+type IdempotencyConfig struct {
+	TTL     time.Duration
+	LockTTL time.Duration
+}
+
+// CacheConfig picks the cache backend. "redis" is shared by every replica, "memory" keeps
+// the cache, rate limits and idempotency keys in process so a single node runs without redis.
+// A zero UserTTL reads users straight from the database.
+type CacheConfig struct {
+	Driver          string
+	MemoryMaxKeys   int
+	JanitorInterval time.Duration
+	UserTTL         time.Duration
+}
+
+// RateLimitRule allows Limit requests per Period to each client of a route group.
+// KeyBy is "ip", "user" or "api_key", a zero Limit turns the group's limit off.
+type RateLimitRule struct {
+	Limit  int
+	Period time.Duration
+	KeyBy  string
+}
+
+type RateLimitConfig struct {
+	Auth  RateLimitRule
+	User  RateLimitRule
+	Admin RateLimitRule
+}
+
+type JobsConfig struct {
+	SuspensionSweepInterval time.Duration
+	TrashRetention          time.Duration
+	TrashPurgeInterval      time.Duration
+	TrashPurgeDryRun        bool
+	AuditCheckpointInterval time.Duration
+	OutboxPollInterval      time.Duration
+	OutboxBatchSize         int
+	OutboxRetention         time.Duration
+	WorkerDrainTimeout      time.Duration
+	LockDriver              string
+	LeaderLockTTL           time.Duration
+	AuditRetentionMonths    int
+	AuditArchiveDir         string
+	AuditArchiveInterval    time.Duration
 }
 
 type Config struct {
-	Server ServerConfig
-	DB     DatabaseConfig
-	JWT    JWTConfig
-	Redis  RedisConfig
-	NATS   NATSConfig
+	Server      ServerConfig
+	DB          DatabaseConfig
+	JWT         JWTConfig
+	Redis       RedisConfig
+	NATS        NATSConfig
+	Events      EventsConfig
+	Webhooks    WebhookConfig
+	Idempotency IdempotencyConfig
+	RateLimits  RateLimitConfig
+	Cache       CacheConfig
+	Jobs        JobsConfig
 }
 
 func getEnv(key, defaultValue string) string {
@@ -91,9 +181,13 @@ func LoadConfig() (*Config, error) {
 		NATS: NATSConfig{
 			URL: getEnv("NATS_URL", "nats://localhost:4222"),
 		},
+
+		Events: EventsConfig{
+			Driver: getEnv("EVENTS_DRIVER", "nats"),
+		},
 	}
-	// Validate required configuration (Password is critical)
-	if cfg.DB.Password == "" {
+	// Validate required configuration (Password is critical), sqlite has none
+	if cfg.DB.Password == "" && getEnv("DB_DRIVER", "sqlx") != "sqlite" {
 		return nil, fmt.Errorf("DB_PASSWORD must be set in the environment or .env file")
 	}
 
@@ -105,9 +199,73 @@ func LoadConfig() (*Config, error) {
 	}
 	cfg.DB.PoolSize = poolSize
 
+	txRetries, err := parseInt("TX_MAX_RETRIES", "3", "must not be negative", nonNegative)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.TxMaxRetries = txRetries
+
+	txBaseDelay, err := parseDuration("TX_RETRY_BASE_DELAY", "20ms", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.TxRetryBaseDelay = txBaseDelay
+
+	txMaxDelay, err := parseDuration("TX_RETRY_MAX_DELAY", "500ms", "must be at least TX_RETRY_BASE_DELAY",
+		func(d time.Duration) bool { return d >= txBaseDelay })
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.TxRetryMaxDelay = txMaxDelay
+
+	// Comma separated, empty keeps every read on the primary
+	for _, dsn := range strings.Split(os.Getenv("DB_REPLICA_DSNS"), ",") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			cfg.DB.ReplicaDSNs = append(cfg.DB.ReplicaDSNs, dsn)
+		}
+	}
+
+	replicaCheck, err := parseDuration("DB_REPLICA_CHECK_INTERVAL", "5s", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.ReplicaCheckInterval = replicaCheck
+
+	cfg.DB.Driver = getEnv("DB_DRIVER", "sqlx")
+	switch cfg.DB.Driver {
+	case "sqlx":
+	case "pgx", "sqlite":
+		if len(cfg.DB.ReplicaDSNs) > 0 {
+			return nil, fmt.Errorf("DB_REPLICA_DSNS only works with DB_DRIVER=sqlx")
+		}
+	default:
+		return nil, fmt.Errorf("invalid DB_DRIVER %q: use sqlx, pgx or sqlite", cfg.DB.Driver)
+	}
+	cfg.DB.SQLitePath = getEnv("SQLITE_PATH", "./dev.db")
+
+	minConns, err := parseInt("DB_MIN_CONNS", "2", "must be between 0 and DB_POOL_SIZE",
+		func(n int) bool { return n >= 0 && n <= cfg.DB.PoolSize })
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.MinConns = minConns
+
+	healthCheck, err := parseDuration("DB_HEALTH_CHECK_PERIOD", "1m", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DB.HealthCheckPeriod = healthCheck
+
+	cfg.DB.StatementCacheMode = getEnv("DB_STATEMENT_CACHE_MODE", "cache_statement")
+	switch cfg.DB.StatementCacheMode {
+	case "cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol":
+	default:
+		return nil, fmt.Errorf("invalid DB_STATEMENT_CACHE_MODE %q: use cache_statement, cache_describe, describe_exec, exec or simple_protocol", cfg.DB.StatementCacheMode)
+	}
+
 	// REDIS DB ENV
 
-	redisDBStr := getEnv("REDIS_DB", "o")
+	redisDBStr := getEnv("REDIS_DB", "0")
 	redisDb, err := strconv.Atoi(redisDBStr)
 	if err != nil {
 		return nil, fmt.Errorf("Invaild redis DB value: %w", err)
@@ -127,5 +285,267 @@ func LoadConfig() (*Config, error) {
 	}
 	cfg.JWT.RefreshTTL = refreshTTL
 
+	// Deliveries before the audit worker dead letters an event
+	maxDeliver, err := parseInt("AUDIT_MAX_DELIVER", "5", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.NATS.MaxDeliver = maxDeliver
+
+	batchSize, err := parseInt("AUDIT_BATCH_SIZE", "100", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.NATS.BatchSize = batchSize
+
+	concurrency, err := parseInt("AUDIT_WORKER_CONCURRENCY", "1", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.NATS.Concurrency = concurrency
+
+	if cfg.Events.Driver != "nats" && cfg.Events.Driver != "memory" {
+		return nil, fmt.Errorf("invalid EVENTS_DRIVER %q: use nats or memory", cfg.Events.Driver)
+	}
+	// The outbox behind the nats driver is a postgres table
+	if cfg.DB.Driver == "sqlite" && cfg.Events.Driver != "memory" {
+		return nil, fmt.Errorf("DB_DRIVER=sqlite needs EVENTS_DRIVER=memory")
+	}
+
+	memoryBuffer, err := parseInt("EVENTS_MEMORY_BUFFER", "1024", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Events.MemoryBuffer = memoryBuffer
+
+	memorySync, err := strconv.ParseBool(getEnv("EVENTS_MEMORY_SYNC", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVENTS_MEMORY_SYNC: %w", err)
+	}
+	cfg.Events.MemorySync = memorySync
+
+	// Outgoing webhooks
+	webhookTimeout, err := parseDuration("WEBHOOK_TIMEOUT", "10s", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Webhooks.Timeout = webhookTimeout
+
+	// With the default backoff 10 attempts spread over roughly four hours
+	webhookAttempts, err := parseInt("WEBHOOK_MAX_ATTEMPTS", "10", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Webhooks.MaxAttempts = webhookAttempts
+
+	webhookPoll, err := parseDuration("WEBHOOK_POLL_INTERVAL", "2s", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Webhooks.PollInterval = webhookPoll
+
+	webhookBatch, err := parseInt("WEBHOOK_BATCH_SIZE", "20", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Webhooks.BatchSize = webhookBatch
+
+	// Idempotency keys
+	idempotencyTTL, err := parseDuration("IDEMPOTENCY_TTL", "24h", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Idempotency.TTL = idempotencyTTL
+
+	// Should outlast the slowest request, a lock that expires early lets a duplicate through
+	idempotencyLockTTL, err := parseDuration("IDEMPOTENCY_LOCK_TTL", "1m", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Idempotency.LockTTL = idempotencyLockTTL
+
+	// Caching
+	cfg.Cache.Driver = getEnv("CACHE_DRIVER", "redis")
+	if cfg.Cache.Driver != "redis" && cfg.Cache.Driver != "memory" {
+		return nil, fmt.Errorf("invalid CACHE_DRIVER %q: use redis or memory", cfg.Cache.Driver)
+	}
+
+	memoryMaxKeys, err := parseInt("CACHE_MEMORY_MAX_KEYS", "100000", "must not be negative", nonNegative)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Cache.MemoryMaxKeys = memoryMaxKeys
+
+	janitorInterval, err := parseDuration("CACHE_JANITOR_INTERVAL", "1m", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Cache.JanitorInterval = janitorInterval
+
+	userCacheTTL, err := parseDuration("CACHE_USER_TTL", "5m", "must not be negative", nonNegative)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Cache.UserTTL = userCacheTTL
+
+	// Rate limits, the auth routes are public so they are keyed by IP
+	if cfg.RateLimits.Auth, err = parseRateLimit("RATE_LIMIT_AUTH", "10/1m", "ip"); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimits.User, err = parseRateLimit("RATE_LIMIT_USER", "120/1m", "user"); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimits.Admin, err = parseRateLimit("RATE_LIMIT_ADMIN", "300/1m", "user"); err != nil {
+		return nil, err
+	}
+
+	// Background jobs
+	sweepInterval, err := parseDuration("SUSPENSION_SWEEP_INTERVAL", "1m", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.SuspensionSweepInterval = sweepInterval
+
+	trashRetention, err := parseDuration("TRASH_RETENTION", "720h", "must be positive", positive) // Default 30 days
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.TrashRetention = trashRetention
+
+	purgeInterval, err := parseDuration("TRASH_PURGE_INTERVAL", "1h", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.TrashPurgeInterval = purgeInterval
+
+	purgeDryRun, err := strconv.ParseBool(getEnv("TRASH_PURGE_DRY_RUN", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_PURGE_DRY_RUN: %w", err)
+	}
+	cfg.Jobs.TrashPurgeDryRun = purgeDryRun
+
+	checkpointInterval, err := parseDuration("AUDIT_CHECKPOINT_INTERVAL", "1h", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.AuditCheckpointInterval = checkpointInterval
+
+	outboxPoll, err := parseDuration("OUTBOX_POLL_INTERVAL", "1s", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.OutboxPollInterval = outboxPoll
+
+	outboxBatch, err := parseInt("OUTBOX_BATCH_SIZE", "100", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.OutboxBatchSize = outboxBatch
+
+	outboxRetention, err := parseDuration("OUTBOX_RETENTION", "168h", "must be positive", positive) // Default 7 days
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.OutboxRetention = outboxRetention
+
+	drainTimeout, err := parseDuration("WORKER_DRAIN_TIMEOUT", "15s", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.WorkerDrainTimeout = drainTimeout
+
+	// Job locks, postgres needs nothing extra, redis needs the redis cache driver
+	cfg.Jobs.LockDriver = getEnv("LOCK_DRIVER", "postgres")
+	switch cfg.Jobs.LockDriver {
+	case "postgres":
+		if cfg.DB.Driver == "sqlite" {
+			return nil, fmt.Errorf("LOCK_DRIVER=postgres needs postgres, use memory with DB_DRIVER=sqlite")
+		}
+	case "memory":
+	case "redis":
+		if cfg.Cache.Driver != "redis" {
+			return nil, fmt.Errorf("LOCK_DRIVER=redis needs CACHE_DRIVER=redis")
+		}
+	default:
+		return nil, fmt.Errorf("invalid LOCK_DRIVER %q: use postgres, redis or memory", cfg.Jobs.LockDriver)
+	}
+
+	// A replica that dies hands its singleton jobs over within this long
+	leaderTTL, err := parseDuration("LEADER_LOCK_TTL", "15s", "must be at least 3s",
+		func(d time.Duration) bool { return d >= 3*time.Second })
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.LeaderLockTTL = leaderTTL
+
+	// Audit partitions older than this are archived to AUDIT_ARCHIVE_DIR and dropped
+	retentionMonths, err := parseInt("AUDIT_RETENTION_MONTHS", "12", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.AuditRetentionMonths = retentionMonths
+	cfg.Jobs.AuditArchiveDir = getEnv("AUDIT_ARCHIVE_DIR", "./archive")
+
+	archiveInterval, err := parseDuration("AUDIT_ARCHIVE_INTERVAL", "24h", "must be positive", positive)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Jobs.AuditArchiveInterval = archiveInterval
+
 	return cfg, nil
 }
+
+// parseInt reads name as an int, an error names the offending value and what was wanted
+func parseInt(name, defaultValue, want string, valid func(int) bool) (int, error) {
+	value := getEnv(name, defaultValue)
+	n, err := strconv.Atoi(value)
+	if err != nil || !valid(n) {
+		return 0, fmt.Errorf("invalid %s: %q, %s", name, value, want)
+	}
+	return n, nil
+}
+
+// parseDuration is parseInt for durations like 1m or 24h
+func parseDuration(name, defaultValue, want string, valid func(time.Duration) bool) (time.Duration, error) {
+	value := getEnv(name, defaultValue)
+	d, err := time.ParseDuration(value)
+	if err != nil || !valid(d) {
+		return 0, fmt.Errorf("invalid %s: %q, %s", name, value, want)
+	}
+	return d, nil
+}
+
+func positive[T int | time.Duration](v T) bool    { return v > 0 }
+func nonNegative[T int | time.Duration](v T) bool { return v >= 0 }
+
+// parseRateLimit reads "<limit>/<period>" like "10/1m" from name, "off" turns the limit off.
+// The key comes from name_KEY.
+func parseRateLimit(name, defaultRule, defaultKey string) (RateLimitRule, error) {
+	rule := RateLimitRule{KeyBy: getEnv(name+"_KEY", defaultKey)}
+	switch rule.KeyBy {
+	case "ip", "user", "api_key":
+	default:
+		return rule, fmt.Errorf("invalid %s_KEY: %q, want ip, user or api_key", name, rule.KeyBy)
+	}
+
+	value := getEnv(name, defaultRule)
+	if value == "off" {
+		return rule, nil
+	}
+
+	limit, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return rule, fmt.Errorf("invalid %s: %q, want <limit>/<period> like 10/1m", name, value)
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		return rule, fmt.Errorf("invalid %s: %q, want <limit>/<period> like 10/1m", name, value)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d < time.Second {
+		return rule, fmt.Errorf("invalid %s: %q, the period must be at least 1s", name, value)
+	}
+
+	rule.Limit, rule.Period = n, d
+	return rule, nil
+}
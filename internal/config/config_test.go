@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigNamesOutOfRangeValues(t *testing.T) {
+	tests := []struct {
+		env, value, want string
+	}{
+		{"TRASH_RETENTION", "-1h", `invalid TRASH_RETENTION: "-1h", must be positive`},
+		{"TRASH_RETENTION", "soon", `invalid TRASH_RETENTION: "soon", must be positive`},
+		{"CACHE_MEMORY_MAX_KEYS", "-5", `invalid CACHE_MEMORY_MAX_KEYS: "-5", must not be negative`},
+		{"LEADER_LOCK_TTL", "1s", `invalid LEADER_LOCK_TTL: "1s", must be at least 3s`},
+		{"DB_MIN_CONNS", "99", `invalid DB_MIN_CONNS: "99", must be between 0 and DB_POOL_SIZE`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv("GO_ENV", "test")
+			t.Setenv("DB_DRIVER", "sqlite")
+			t.Setenv("EVENTS_DRIVER", "memory")
+			t.Setenv("LOCK_DRIVER", "memory")
+			t.Setenv(tt.env, tt.value)
+
+			_, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	t.Setenv("GO_ENV", "test")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("EVENTS_DRIVER", "memory")
+	t.Setenv("LOCK_DRIVER", "memory")
+
+	if _, err := LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,266 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/google/uuid"
+)
+
+func newAudit(actorID string, payload map[string]any) domain.Audit {
+	id, _ := uuid.NewV7()
+	return domain.Audit{
+		UUID:      id.String(),
+		EventType: "contract.checked",
+		ActorID:   actorID,
+		Payload:   payload,
+	}
+}
+
+var auditCases = []Case{
+	{"audit batch extends the hash chain", func(ctx context.Context, r Repos) error {
+		before, err := r.AuditChain.Head(ctx)
+		if err != nil {
+			return err
+		}
+
+		actor, _ := uuid.NewV7()
+		logs := []domain.Audit{
+			newAudit(actor.String(), map[string]any{"step": "one"}),
+			newAudit("", map[string]any{"step": "two", "n": 2}),
+		}
+		if err := r.Audit.CreateBatch(ctx, logs); err != nil {
+			return err
+		}
+
+		head, err := r.AuditChain.Head(ctx)
+		if err != nil {
+			return err
+		}
+		if err := expect("head seq", head.Seq, before.Seq+2); err != nil {
+			return err
+		}
+
+		// Walk the rows just written and recompute every link
+		var walked []*domain.Audit
+		err = r.AuditChain.Walk(ctx, before.CreatedAt, head.CreatedAt.Add(time.Microsecond), func(a *domain.Audit) error {
+			if a.ChainSeq > before.Seq {
+				walked = append(walked, a)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := expect("walked rows", len(walked), 2); err != nil {
+			return err
+		}
+
+		prev := before.Hash
+		for _, a := range walked {
+			if err := expect("prev hash", a.PrevHash, prev); err != nil {
+				return err
+			}
+			hash, err := domain.ChainHash(a.PrevHash, *a)
+			if err != nil {
+				return err
+			}
+			if err := expect(fmt.Sprintf("hash of seq %d", a.ChainSeq), a.Hash, hash); err != nil {
+				return err
+			}
+			prev = a.Hash
+		}
+		if err := expect("actor", walked[1].ActorID, ""); err != nil {
+			return err
+		}
+		return expect("head hash", head.Hash, prev)
+	}},
+
+	{"audit ingestion skips event ids already stored", func(ctx context.Context, r Repos) error {
+		actor, _ := uuid.NewV7()
+		first := newAudit(actor.String(), map[string]any{"step": "one"})
+		if err := r.Audit.Create(ctx, first); err != nil {
+			return err
+		}
+		before, err := r.AuditChain.Head(ctx)
+		if err != nil {
+			return err
+		}
+
+		// A redelivery, a batch repeating it and an id twice in one batch
+		second := newAudit(actor.String(), map[string]any{"step": "two"})
+		if err := r.Audit.Create(ctx, first); err != nil {
+			return err
+		}
+		if err := r.Audit.CreateBatch(ctx, []domain.Audit{first, second, second}); err != nil {
+			return err
+		}
+
+		head, err := r.AuditChain.Head(ctx)
+		if err != nil {
+			return err
+		}
+		if err := expect("head seq", head.Seq, before.Seq+1); err != nil {
+			return err
+		}
+
+		got, err := r.Audit.Query(ctx, domain.AuditFilter{
+			ActorID: actor.String(),
+			From:    time.Now().Add(-time.Hour),
+			To:      time.Now().Add(time.Hour),
+			Limit:   10,
+		})
+		if err != nil {
+			return err
+		}
+		return expect("stored rows", len(got), 2)
+	}},
+
+	{"audit query filters and pages newest first", func(ctx context.Context, r Repos) error {
+		actor, _ := uuid.NewV7()
+		tag := actor.String()
+		for _, step := range []string{"one", "two", "three"} {
+			if err := r.Audit.Create(ctx, newAudit(actor.String(), map[string]any{"tag": tag, "step": step})); err != nil {
+				return err
+			}
+		}
+
+		filter := domain.AuditFilter{
+			ActorID:      actor.String(),
+			From:         time.Now().Add(-time.Hour),
+			To:           time.Now().Add(time.Hour),
+			PayloadKey:   "step",
+			PayloadValue: "two",
+			Limit:        10,
+		}
+		got, err := r.Audit.Query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if err := expect("payload match rows", len(got), 1); err != nil {
+			return err
+		}
+		if err := expect("payload step", got[0].Payload["step"], any("two")); err != nil {
+			return err
+		}
+
+		filter.PayloadKey, filter.PayloadValue, filter.Limit = "tag", tag, 2
+		page, err := r.Audit.Query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if err := expect("first page rows", len(page), 2); err != nil {
+			return err
+		}
+		if err := expect("newest first", page[0].Payload["step"], any("three")); err != nil {
+			return err
+		}
+
+		last := page[len(page)-1]
+		filter.After = &domain.AuditCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		page, err = r.Audit.Query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if err := expect("second page rows", len(page), 1); err != nil {
+			return err
+		}
+		return expect("oldest last", page[0].Payload["step"], any("one"))
+	}},
+
+	{"audit payload filter matches numbers and booleans", func(ctx context.Context, r Repos) error {
+		actor, _ := uuid.NewV7()
+		for _, payload := range []map[string]any{
+			{"n": 2, "row": "number"},
+			{"n": "2", "row": "string"},
+			{"n": 2.5, "row": "fraction"},
+			{"n": true, "row": "bool"},
+			{"n": []any{2}, "row": "array"},
+		} {
+			if err := r.Audit.Create(ctx, newAudit(actor.String(), payload)); err != nil {
+				return err
+			}
+		}
+
+		filter := domain.AuditFilter{
+			ActorID:    actor.String(),
+			From:       time.Now().Add(-time.Hour),
+			To:         time.Now().Add(time.Hour),
+			PayloadKey: "n",
+			Limit:      10,
+		}
+		for value, want := range map[string][]string{
+			"2":     {"number", "string"},
+			"2.0":   {"number"},
+			"2.5":   {"fraction"},
+			"true":  {"bool"},
+			"false": nil,
+		} {
+			filter.PayloadValue = value
+			got, err := r.Audit.Query(ctx, filter)
+			if err != nil {
+				return err
+			}
+			rows := map[string]bool{}
+			for _, a := range got {
+				rows[fmt.Sprint(a.Payload["row"])] = true
+			}
+			if len(rows) != len(want) {
+				return fmt.Errorf("n = %s matched %v, want %v", value, rows, want)
+			}
+			for _, w := range want {
+				if !rows[w] {
+					return fmt.Errorf("n = %s matched %v, want %v", value, rows, want)
+				}
+			}
+		}
+		return nil
+	}},
+
+	{"audit checkpoints", func(ctx context.Context, r Repos) error {
+		head, err := r.AuditChain.Head(ctx)
+		if err != nil {
+			return err
+		}
+
+		cp := &domain.Checkpoint{Seq: head.Seq, Hash: head.Hash, ChainAt: head.CreatedAt, Signature: "contract"}
+		if err := r.AuditChain.CreateCheckpoint(ctx, cp); err != nil {
+			return err
+		}
+		if cp.ID == 0 || cp.CreatedAt.IsZero() {
+			return fmt.Errorf("generated columns not filled: id %d created %v", cp.ID, cp.CreatedAt)
+		}
+
+		latest, err := r.AuditChain.LatestCheckpoint(ctx)
+		if err != nil {
+			return err
+		}
+		if err := expect("latest checkpoint seq", latest.Seq, head.Seq); err != nil {
+			return err
+		}
+
+		before, err := r.AuditChain.CheckpointBefore(ctx, head.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := expect("checkpoint at or before the head", before.ID, cp.ID); err != nil {
+			return err
+		}
+		if _, err := r.AuditChain.CheckpointBefore(ctx, time.Unix(0, 0)); expectCode(err, domain.CodeNotFound) != nil {
+			return fmt.Errorf("checkpoint before the epoch: %v", err)
+		}
+
+		list, err := r.AuditChain.Checkpoints(ctx, head.CreatedAt, head.CreatedAt.Add(time.Microsecond))
+		if err != nil {
+			return err
+		}
+		for _, c := range list {
+			if c.ID == cp.ID {
+				return nil
+			}
+		}
+		return fmt.Errorf("checkpoints over the head's time miss %d", cp.ID)
+	}},
+}
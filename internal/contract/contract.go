@@ -0,0 +1,89 @@
+// Package contract
+// contract holds the behaviour every user and audit repository adapter has to share,
+// so postgres and sqlite can be checked against the same cases. Each adapter package runs them
+// in its contract_test.go, cmd/repocontract runs them against a configured database.
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/google/uuid"
+)
+
+// Repos is one backend's set of adapters. The cases write real rows, point them at a throwaway database.
+type Repos struct {
+	Users       ports.UserRepository
+	Suspensions ports.SuspensionRepository
+	Audit       ports.AuditRepository
+	AuditChain  ports.AuditChainRepository
+	Tx          ports.TxManager
+}
+
+type Case struct {
+	Name string
+	Run  func(ctx context.Context, r Repos) error
+}
+
+// Result is the outcome of one case, Err is nil when it passed
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Cases is the whole suite, in the order it runs
+var Cases = append(append(userCases, suspensionCases...), auditCases...)
+
+// Run runs every case against r, a failing case does not stop the ones after it
+func Run(ctx context.Context, r Repos) []Result {
+	results := make([]Result, 0, len(Cases))
+	for _, c := range Cases {
+		results = append(results, Result{Name: c.Name, Err: c.Run(ctx, r)})
+	}
+	return results
+}
+
+// newUser builds a user no other case or run collides with
+func newUser() domain.User {
+	id, _ := uuid.NewV7()
+	tag := id.String()[24:]
+	return domain.User{
+		UUID:     id.String(),
+		UserName: "contract_" + tag,
+		Email:    "contract_" + tag + "@example.com",
+		Phone:    "+880" + tag,
+		Password: "hash",
+		UserRole: "user",
+	}
+}
+
+// createUser stores a fresh user and hands back what the repository filled in
+func createUser(ctx context.Context, r Repos) (*domain.User, error) {
+	u := newUser()
+	if err := r.Users.Create(ctx, &u); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	return &u, nil
+}
+
+// expectCode checks err is an AppError carrying code
+func expectCode(err error, code domain.ErrorCode) error {
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) {
+		return fmt.Errorf("want a %s AppError, got %v", code, err)
+	}
+	if appErr.Code != code {
+		return fmt.Errorf("want code %s, got %s (%v)", code, appErr.Code, err)
+	}
+	return nil
+}
+
+func expect[T comparable](what string, got, want T) error {
+	if got != want {
+		return fmt.Errorf("%s: got %v, want %v", what, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/google/uuid"
+)
+
+func newSuspension(userID string, expires *time.Time) *domain.Suspension {
+	id, _ := uuid.NewV7()
+	by, _ := uuid.NewV7()
+	return &domain.Suspension{
+		UUID:        id.String(),
+		UserID:      userID,
+		SuspendedBy: by.String(),
+		Reason:      "contract",
+		ExpiresAt:   expires,
+	}
+}
+
+var suspensionCases = []Case{
+	{"suspension one open at a time", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		s := newSuspension(u.UUID, nil)
+		if err := r.Suspensions.Create(ctx, s); err != nil {
+			return err
+		}
+		if s.ID == 0 || s.SuspendedAt.IsZero() {
+			return fmt.Errorf("generated columns not filled: id %d suspended %v", s.ID, s.SuspendedAt)
+		}
+
+		err = r.Suspensions.Create(ctx, newSuspension(u.UUID, nil))
+		if err := expectCode(err, domain.CodeConflict); err != nil {
+			return fmt.Errorf("second open suspension: %w", err)
+		}
+
+		active, err := r.Suspensions.ReadActive(ctx, u.UUID)
+		if err != nil {
+			return err
+		}
+		return expect("active suspension", active.UUID, s.UUID)
+	}},
+
+	{"suspension expiry, lift and history", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		expired := time.Now().Add(-time.Minute)
+		s := newSuspension(u.UUID, &expired)
+		if err := r.Suspensions.Create(ctx, s); err != nil {
+			return err
+		}
+
+		due, err := r.Suspensions.ListExpired(ctx, time.Now(), 1000)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, d := range due {
+			found = found || d.UUID == s.UUID
+		}
+		if !found {
+			return fmt.Errorf("list expired misses %s", s.UUID)
+		}
+
+		if err := r.Suspensions.Lift(ctx, s.UUID, nil, "expired"); err != nil {
+			return err
+		}
+		if _, err := r.Suspensions.ReadActive(ctx, u.UUID); expectCode(err, domain.CodeNotFound) != nil {
+			return fmt.Errorf("lifted suspension still active: %v", err)
+		}
+
+		// A new suspension may open once the last one is lifted
+		if err := r.Suspensions.Create(ctx, newSuspension(u.UUID, nil)); err != nil {
+			return err
+		}
+		history, err := r.Suspensions.History(ctx, u.UUID)
+		if err != nil {
+			return err
+		}
+		if err := expect("history rows", len(history), 2); err != nil {
+			return err
+		}
+		if history[1].LiftedAt == nil {
+			return fmt.Errorf("history is not newest first")
+		}
+		return nil
+	}},
+
+	{"expired suspensions of trashed users are not listed", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		expired := time.Now().Add(-time.Minute)
+		s := newSuspension(u.UUID, &expired)
+		if err := r.Suspensions.Create(ctx, s); err != nil {
+			return err
+		}
+		if err := r.Users.SoftDelete(ctx, u.UUID); err != nil {
+			return err
+		}
+
+		listed := func() (bool, error) {
+			due, err := r.Suspensions.ListExpired(ctx, time.Now(), 1000)
+			for _, d := range due {
+				if d.UUID == s.UUID {
+					return true, err
+				}
+			}
+			return false, err
+		}
+
+		if found, err := listed(); err != nil || found {
+			return fmt.Errorf("trashed user's suspension listed: %v %v", found, err)
+		}
+		if err := r.Users.Restore(ctx, u.UUID, domain.StatusSuspended); err != nil {
+			return err
+		}
+		if found, err := listed(); err != nil || !found {
+			return fmt.Errorf("restored user's suspension not listed: %v %v", found, err)
+		}
+		return nil
+	}},
+
+	{"suspension of a missing user is rejected", func(ctx context.Context, r Repos) error {
+		err := r.Suspensions.Create(ctx, newSuspension(newUser().UUID, nil))
+		return expectCode(err, domain.CodeValidation)
+	}},
+}
@@ -0,0 +1,262 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+var errRollback = errors.New("contract: roll back")
+
+var userCases = []Case{
+	{"user create fills generated columns", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+		if u.ID == 0 || u.CreatedAt.IsZero() || u.UpdatedAt.IsZero() {
+			return fmt.Errorf("generated columns not filled: id %d created %v updated %v", u.ID, u.CreatedAt, u.UpdatedAt)
+		}
+		if err := expect("status", u.UserStatus, domain.StatusActive); err != nil {
+			return err
+		}
+
+		got, err := r.Users.ReadOne(ctx, u.UUID)
+		if err != nil {
+			return fmt.Errorf("read one: %w", err)
+		}
+		if err := expect("email", got.Email, u.Email); err != nil {
+			return err
+		}
+		if err := expect("created_at", got.CreatedAt.UTC(), u.CreatedAt.UTC()); err != nil {
+			return err
+		}
+
+		got, err = r.Users.ReadByEmail(ctx, u.Email)
+		if err != nil {
+			return fmt.Errorf("read by email: %w", err)
+		}
+		return expect("uuid", got.UUID, u.UUID)
+	}},
+
+	{"user unique violation maps to a conflict on the field", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		dup := newUser()
+		dup.Email = u.Email
+		err = r.Users.Create(ctx, &dup)
+		if err := expectCode(err, domain.CodeConflict); err != nil {
+			return err
+		}
+		var appErr *domain.AppError
+		errors.As(err, &appErr)
+		return expect("conflict field", appErr.Field, "email")
+	}},
+
+	{"user check conflict names every taken field", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		conflicts, err := r.Users.CheckConflict(ctx, u.UserName, u.Email, "free-phone")
+		if err != nil {
+			return err
+		}
+		fields := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			fields = append(fields, c.Field)
+		}
+		return expect("conflicting fields", strings.Join(fields, ","), "user_name,email")
+	}},
+
+	{"user missing row is not found", func(ctx context.Context, r Repos) error {
+		_, err := r.Users.ReadOne(ctx, newUser().UUID)
+		return expectCode(err, domain.CodeNotFound)
+	}},
+
+	{"user update only touches the given fields", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		name := u.UserName + "_new"
+		if err := r.Users.Update(ctx, domain.UserUpdate{UUID: u.UUID, UserName: &name}); err != nil {
+			return err
+		}
+
+		got, err := r.Users.ReadOne(ctx, u.UUID)
+		if err != nil {
+			return err
+		}
+		if err := expect("user_name", got.UserName, name); err != nil {
+			return err
+		}
+		return expect("email", got.Email, u.Email)
+	}},
+
+	{"user list filters, orders and pages", func(ctx context.Context, r Repos) error {
+		var made []*domain.User
+		for range 3 {
+			u, err := createUser(ctx, r)
+			if err != nil {
+				return err
+			}
+			made = append(made, u)
+		}
+
+		// Name matching ignores case on both backends
+		got, err := r.Users.ReadAll(ctx, domain.UserFilter{UserName: strings.ToUpper(made[1].UserName)})
+		if err != nil {
+			return err
+		}
+		if len(got) != 1 {
+			return fmt.Errorf("name filter: got %d users, want 1", len(got))
+		}
+
+		page, err := r.Users.ReadAll(ctx, domain.UserFilter{Email: made[2].Email, Limit: 1, Offset: 0})
+		if err != nil {
+			return err
+		}
+		if len(page) != 1 {
+			return fmt.Errorf("email filter: got %d users, want 1", len(page))
+		}
+		return expect("email filter", page[0].UUID, made[2].UUID)
+	}},
+
+	{"user stream leaves secrets out", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		rows := 0
+		err = r.Users.Stream(ctx, domain.UserFilter{Email: u.Email}, func(s *domain.User) error {
+			rows++
+			return expect("streamed password", s.Password, "")
+		})
+		if err != nil {
+			return err
+		}
+		return expect("streamed rows", rows, 1)
+	}},
+
+	{"user trash, restore and prune", func(ctx context.Context, r Repos) error {
+		u, err := createUser(ctx, r)
+		if err != nil {
+			return err
+		}
+		if err := r.Users.SoftDelete(ctx, u.UUID); err != nil {
+			return err
+		}
+
+		if _, err := r.Users.ReadOne(ctx, u.UUID); expectCode(err, domain.CodeNotFound) != nil {
+			return fmt.Errorf("trashed user still readable: %v", err)
+		}
+		trashed, err := r.Users.ReadOneDeleted(ctx, u.UUID)
+		if err != nil {
+			return err
+		}
+		if err := expect("trashed status", trashed.UserStatus, domain.StatusInactive); err != nil {
+			return err
+		}
+		if trashed.StatusBeforeDelete == nil || *trashed.StatusBeforeDelete != domain.StatusActive {
+			return fmt.Errorf("status before delete not kept: %v", trashed.StatusBeforeDelete)
+		}
+
+		list, err := r.Users.Trash(ctx, domain.UserFilter{Email: u.Email})
+		if err != nil {
+			return err
+		}
+		if err := expect("trash rows", len(list), 1); err != nil {
+			return err
+		}
+		before, err := r.Users.ListTrashedBefore(ctx, time.Now().Add(time.Minute), 1000)
+		if err != nil {
+			return err
+		}
+		if !containsUser(before, u.UUID) {
+			return fmt.Errorf("list trashed before misses %s", u.UUID)
+		}
+
+		if err := r.Users.Restore(ctx, u.UUID, domain.StatusActive); err != nil {
+			return err
+		}
+		if _, err := r.Users.ReadOne(ctx, u.UUID); err != nil {
+			return fmt.Errorf("restored user: %w", err)
+		}
+
+		// Only trashed users can be pruned
+		if err := r.Users.Prune(ctx, u.UUID); err != nil {
+			return err
+		}
+		if _, err := r.Users.ReadOne(ctx, u.UUID); err != nil {
+			return fmt.Errorf("active user was pruned: %w", err)
+		}
+		if err := r.Users.SoftDelete(ctx, u.UUID); err != nil {
+			return err
+		}
+		if err := r.Users.Prune(ctx, u.UUID); err != nil {
+			return err
+		}
+		_, err = r.Users.ReadOneDeleted(ctx, u.UUID)
+		return expectCode(err, domain.CodeNotFound)
+	}},
+
+	{"user writes roll back with the transaction", func(ctx context.Context, r Repos) error {
+		u := newUser()
+		committed := false
+		err := r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.Users.Create(ctx, &u); err != nil {
+				return err
+			}
+			if !r.Tx.InTx(ctx) {
+				return fmt.Errorf("InTx is false inside WithinTx")
+			}
+			r.Tx.AfterCommit(ctx, func() { committed = true })
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			return fmt.Errorf("want the rollback error back, got %v", err)
+		}
+		if committed {
+			return fmt.Errorf("after commit hook ran on rollback")
+		}
+		_, err = r.Users.ReadOne(ctx, u.UUID)
+		return expectCode(err, domain.CodeNotFound)
+	}},
+
+	{"user writes commit with the transaction", func(ctx context.Context, r Repos) error {
+		u := newUser()
+		committed := false
+		err := r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			r.Tx.AfterCommit(ctx, func() { committed = true })
+			return r.Users.Create(ctx, &u)
+		})
+		if err != nil {
+			return err
+		}
+		if !committed {
+			return fmt.Errorf("after commit hook did not run")
+		}
+		_, err = r.Users.ReadOne(ctx, u.UUID)
+		return err
+	}},
+}
+
+func containsUser(list []*domain.User, id string) bool {
+	for _, u := range list {
+		if u.UUID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,125 @@
 // this package contains the domain for audit
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Audit struct {
+	ID        int64          `db:"id"`
 	UUID      string         `db:"uuid"`
 	EventType string         `db:"event_type"`
 	ActorID   string         `db:"actor_id"`
 	Payload   map[string]any `db:"payload"`
+	CreatedAt time.Time      `db:"created_at"`
+
+	// Hash chain, filled in when the row is persisted
+	ChainSeq int64  `db:"chain_seq"`
+	PrevHash string `db:"prev_hash"`
+	Hash     string `db:"hash"`
+}
+
+// AuditCursor marks the last row of a page, the next page starts right after it
+type AuditCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// AuditFilter narrows an audit log query. From and To bound created_at (To exclusive)
+// so only the monthly partitions inside the range are scanned.
+type AuditFilter struct {
+	ActorID      string
+	EventType    string
+	From         time.Time
+	To           time.Time
+	PayloadKey   string
+	PayloadValue string
+	After        *AuditCursor
+	Limit        int
+}
+
+// PayloadMatch is how an AuditFilter's PayloadValue compares with the top level payload value
+// under PayloadKey, the same for the stored log and the live stream. The value always matches a
+// string equal to it. If it is also a JSON number it matches numbers of equal value, so 2 finds
+// 2 and 2.0, and true or false match that boolean. Objects, arrays and null never match.
+type PayloadMatch struct {
+	Text   string
+	Number *json.Number
+	Bool   *bool
+}
+
+func NewPayloadMatch(value string) PayloadMatch {
+	m := PayloadMatch{Text: value}
+
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		switch v := v.(type) {
+		case float64:
+			n := json.Number(value)
+			m.Number = &n
+		case bool:
+			m.Bool = &v
+		}
+	}
+	return m
+}
+
+// Values lists the payload values that match, for stores that look values up by equality
+func (m PayloadMatch) Values() []any {
+	values := []any{m.Text}
+	if m.Number != nil {
+		values = append(values, *m.Number)
+	}
+	if m.Bool != nil {
+		values = append(values, *m.Bool)
+	}
+	return values
+}
+
+// Matches reports whether a decoded payload value matches
+func (m PayloadMatch) Matches(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == m.Text
+	case bool:
+		return m.Bool != nil && v == *m.Bool
+	case float64:
+		want, ok := m.float()
+		return ok && v == want
+	case json.Number:
+		got, err := v.Float64()
+		want, ok := m.float()
+		return err == nil && ok && got == want
+	}
+	return false
+}
+
+func (m PayloadMatch) float() (float64, bool) {
+	if m.Number == nil {
+		return 0, false
+	}
+	f, err := m.Number.Float64()
+	return f, err == nil
+}
+
+// AuditPage is one page of audit entries, newest first
+type AuditPage struct {
+	Items []*Audit
+	Next  *AuditCursor
+}
+
+// StreamStart picks where a live audit stream begins. AfterSeq resumes right after
+// a stream sequence, otherwise From replays from a point in time, otherwise only new events are sent.
+type StreamStart struct {
+	AfterSeq uint64
+	From     time.Time
+}
+
+// StreamedAudit is an audit event read from the live stream. Seq is the stream sequence
+// clients resume from, the event is not chained yet so only PublishedAt is known.
+type StreamedAudit struct {
+	Seq         uint64
+	PublishedAt time.Time
+	Audit       Audit
 }
@@ -0,0 +1,28 @@
+// Package domain
+// this one describes archived audit partitions
+package domain
+
+import "time"
+
+// AuditPartition is one monthly partition of the audit log covering [From, To)
+type AuditPartition struct {
+	Schema string
+	Table  string
+	From   time.Time
+	To     time.Time
+}
+
+// ArchiveManifest describes an archived partition. SHA256 and Bytes cover the data file as stored.
+type ArchiveManifest struct {
+	Partition  string    `json:"partition"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Format     string    `json:"format"`
+	DataKey    string    `json:"data_key"`
+	Rows       int64     `json:"rows"`
+	FirstSeq   int64     `json:"first_chain_seq,omitempty"`
+	LastSeq    int64     `json:"last_chain_seq,omitempty"`
+	Bytes      int64     `json:"bytes"`
+	SHA256     string    `json:"sha256"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
@@ -0,0 +1,27 @@
+// Package domain
+// this one describes how audit ingestion keeps up with the event stream
+package domain
+
+import "time"
+
+// IngestStats is a snapshot of the audit worker. Pending is the lag, messages
+// in the stream not yet delivered. The counters run since the process started.
+type IngestStats struct {
+	Workers     int
+	BatchSize   int
+	Connected   bool
+	Pending     uint64
+	InFlight    int
+	Redelivered int
+
+	Stored          uint64
+	Batches         uint64
+	FallbackBatches uint64
+	Retried         uint64
+	DeadLettered    uint64
+
+	// PerSecond is the average number of events stored per second over the last minute
+	PerSecond     float64
+	LastBatchAt   *time.Time
+	LastBatchTook time.Duration
+}
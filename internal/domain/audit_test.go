@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPayloadMatch(t *testing.T) {
+	cases := []struct {
+		value   string
+		payload any
+		want    bool
+	}{
+		{"two", "two", true},
+		{"two", "Two", false},
+		{"2", "2", true},
+		{"2", float64(2), true},
+		{"2.0", float64(2), true},
+		{"2.0", "2", false},
+		{"2", json.Number("2.00"), true},
+		{"2", float64(3), false},
+		{"two", float64(2), false},
+		{"true", true, true},
+		{"true", "true", true},
+		{"true", false, false},
+		{"1", true, false},
+		{"null", nil, false},
+		{"2", []any{float64(2)}, false},
+		{"2", map[string]any{"n": float64(2)}, false},
+	}
+
+	for _, c := range cases {
+		if got := NewPayloadMatch(c.value).Matches(c.payload); got != c.want {
+			t.Errorf("%q matches %#v: %v, want %v", c.value, c.payload, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package domain
+// this one carries request scoped identity through context
+package domain
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor stores the id of the user performing the current request
+func WithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+// ActorFromContext returns the acting user id, empty for system initiated work
+func ActorFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(actorKey{}).(string)
+	return actorID
+}
+
+type correlationKey struct{}
+
+// WithCorrelationID tags the context so every event raised while handling it can be traced back together
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation id, empty outside of a request
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads sends the reads made with ctx to the primary database instead of a replica,
+// so a caller sees its own writes before they have replicated
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether reads made with ctx must go to the primary
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+type secretsKey struct{}
+
+// WithSecrets marks the user reads made with ctx as needing the password hash and otp.
+// Caches leave those out, so the reads go to the database.
+func WithSecrets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, secretsKey{}, true)
+}
+
+// SecretsWanted reports whether user reads made with ctx must carry the secrets
+func SecretsWanted(ctx context.Context) bool {
+	wanted, _ := ctx.Value(secretsKey{}).(bool)
+	return wanted
+}
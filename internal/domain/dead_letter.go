@@ -0,0 +1,23 @@
+// Package domain
+// this one holds events a subscriber gave up on
+package domain
+
+import "time"
+
+// DeadLetter is an event a consumer could not handle, kept for inspection and replay.
+// Replaying it delivers the event to Consumer again, not to every subscriber.
+type DeadLetter struct {
+	Seq        uint64
+	Consumer   string
+	Subject    string
+	Reason     string
+	Deliveries int
+	FailedAt   time.Time
+	Payload    []byte
+}
+
+// DeadLetterFilter pages through the dead letter queue oldest first
+type DeadLetterFilter struct {
+	AfterSeq uint64
+	Limit    int
+}
@@ -0,0 +1,104 @@
+// Package domain
+// this one builds field level diffs for audit events
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+// Redacted replaces secret values in diffs, the change itself is still recorded
+const Redacted = "[REDACTED]"
+
+// FieldChange is the before and after value of a single field
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// userSecrets are never written to the audit log in clear
+var userSecrets = map[string]bool{
+	"password": true,
+	"otp":      true,
+}
+
+// UserSnapshot flattens the auditable fields of a user, nil gives an empty snapshot
+func UserSnapshot(u *User) map[string]any {
+	if u == nil {
+		return map[string]any{}
+	}
+	return map[string]any{
+		"user_name":            u.UserName,
+		"email":                u.Email,
+		"phone":                u.Phone,
+		"password":             u.Password,
+		"otp":                  derefString(u.OTP),
+		"user_status":          u.UserStatus,
+		"user_role":            u.UserRole,
+		"status_before_delete": derefString(u.StatusBeforeDelete),
+		"deleted_at":           formatTime(u.DeletedAt),
+	}
+}
+
+// DiffUsers lists the fields that differ between two states of a user.
+// before is nil for creations and after is nil for hard deletes. Secrets are redacted.
+func DiffUsers(before, after *User) map[string]FieldChange {
+	return diffSnapshots(UserSnapshot(before), UserSnapshot(after), userSecrets)
+}
+
+// diffSnapshots compares two snapshots, a missing key counts as an empty value
+func diffSnapshots(before, after map[string]any, secrets map[string]bool) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		from, to := orEmpty(before[k]), orEmpty(after[k])
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+
+		change := FieldChange{From: from, To: to}
+		if secrets[k] {
+			change.From = redact(from)
+			change.To = redact(to)
+		}
+		changes[k] = change
+	}
+
+	return changes
+}
+
+func orEmpty(v any) any {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+func redact(v any) any {
+	if v == "" {
+		return ""
+	}
+	return Redacted
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -13,9 +13,20 @@ const (
 	CodeInternal    ErrorCode = "INTERNAL"
 	CodeValidation  ErrorCode = "VALIDATION"
 	CodeUauthorized ErrorCode = "UNAUTHORIZED"
+	CodeUnavailable ErrorCode = "UNAVAILABLE"
+
+	// Account
+	CodeSuspended ErrorCode = "ACCOUNT_SUSPENDED"
 
 	//Token
 	CodeInvalidToken ErrorCode = "INVALID_TOKEN"
+
+	// Idempotency
+	CodeIdempotencyKeyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInFlight  ErrorCode = "IDEMPOTENCY_IN_FLIGHT"
+
+	// Rate limiting
+	CodeRateLimited ErrorCode = "RATE_LIMITED"
 )
 
 type ErrorItem struct {
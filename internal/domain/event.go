@@ -0,0 +1,249 @@
+// Package domain
+// this one holds the domain events published on the event bus
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// EventVersion is the envelope version, bumped when the envelope or an event payload changes incompatibly
+const EventVersion = 1
+
+// EventSubjectPrefix namespaces event types on the broker, user.suspended travels on events.user.suspended
+const EventSubjectPrefix = "events."
+
+// Event types published by the services
+const (
+	EventUserRegistered  = "user.registered"
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserDeleted     = "user.deleted"
+	EventUserRestored    = "user.restored"
+	EventUserPruned      = "user.pruned"
+	EventUserPurged      = "user.purged"
+	EventUserSuspended   = "user.suspended"
+	EventUserUnsuspended = "user.unsuspended"
+	EventUserReinstated  = "user.reinstated"
+	EventUsersExported   = "user.exported"
+
+	EventAuthLogin = "auth.login"
+)
+
+// Event is the versioned envelope every domain event travels in.
+// Data holds the JSON of the typed event named by Type.
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	ActorID       string          `json:"actor_id,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// DomainEvent is a typed event payload that knows its event type
+type DomainEvent interface {
+	EventType() string
+}
+
+// NewEvent wraps e in an envelope, the caller fills in ID, ActorID and CorrelationID
+func NewEvent(e DomainEvent) (Event, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:       e.EventType(),
+		Version:    EventVersion,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}, nil
+}
+
+// Subject is the broker subject the event is published on
+func (e Event) Subject() string {
+	return EventSubjectPrefix + e.Type
+}
+
+// MatchEventType reports whether eventType matches pattern. Patterns are dot separated,
+// * matches exactly one segment and a trailing > matches one or more, so "user.*" or ">".
+func MatchEventType(pattern, eventType string) bool {
+	want := strings.Split(pattern, ".")
+	have := strings.Split(eventType, ".")
+
+	for i, w := range want {
+		if w == ">" {
+			return i == len(want)-1 && len(have) > i
+		}
+		if i >= len(have) || (w != "*" && w != have[i]) {
+			return false
+		}
+	}
+	return len(want) == len(have)
+}
+
+// ValidEventPattern reports whether pattern is usable with MatchEventType
+func ValidEventPattern(pattern string) bool {
+	segments := strings.Split(pattern, ".")
+	for i, s := range segments {
+		if s == "" || (s == ">" && i != len(segments)-1) {
+			return false
+		}
+		if s != "*" && s != ">" && strings.ContainsAny(s, "*> ") {
+			return false
+		}
+	}
+	return true
+}
+
+// UserChanged is the field level diff of a user mutation, embedded by the user events
+type UserChanged struct {
+	Entity   string                 `json:"entity"`
+	EntityID string                 `json:"entity_id"`
+	Changes  map[string]FieldChange `json:"changes"`
+}
+
+// NewUserChanged diffs before and after, before is nil for creations and after for hard deletes
+func NewUserChanged(before, after *User) UserChanged {
+	target := ""
+	switch {
+	case after != nil:
+		target = after.UUID
+	case before != nil:
+		target = before.UUID
+	}
+
+	return UserChanged{
+		Entity:   "user",
+		EntityID: target,
+		Changes:  DiffUsers(before, after),
+	}
+}
+
+// UserRegistered is a self registration, the new user is their own actor
+type UserRegistered struct{ UserChanged }
+
+func (UserRegistered) EventType() string { return EventUserRegistered }
+
+// UserCreated is a user created by an admin
+type UserCreated struct{ UserChanged }
+
+func (UserCreated) EventType() string { return EventUserCreated }
+
+type UserUpdated struct{ UserChanged }
+
+func (UserUpdated) EventType() string { return EventUserUpdated }
+
+// UserDeleted is a soft delete, the user moved to the trash
+type UserDeleted struct{ UserChanged }
+
+func (UserDeleted) EventType() string { return EventUserDeleted }
+
+type UserRestored struct{ UserChanged }
+
+func (UserRestored) EventType() string { return EventUserRestored }
+
+// UserPruned is a hard delete from the trash by an admin
+type UserPruned struct{ UserChanged }
+
+func (UserPruned) EventType() string { return EventUserPruned }
+
+// UserPurged is a hard delete by the trash retention job
+type UserPurged struct {
+	UserChanged
+	Retention string `json:"retention"`
+	Trigger   string `json:"trigger"`
+}
+
+func (UserPurged) EventType() string { return EventUserPurged }
+
+type UserSuspended struct {
+	UserChanged
+	SuspensionID string     `json:"suspension_id"`
+	Reason       string     `json:"reason"`
+	Until        *time.Time `json:"until,omitempty"`
+}
+
+func (UserSuspended) EventType() string { return EventUserSuspended }
+
+// UserUnsuspended is a suspension lifted early by an admin
+type UserUnsuspended struct {
+	UserChanged
+	SuspensionID string `json:"suspension_id,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+func (UserUnsuspended) EventType() string { return EventUserUnsuspended }
+
+// UserReinstated is a suspension that ran out
+type UserReinstated struct {
+	UserChanged
+	SuspensionID string `json:"suspension_id"`
+	Reason       string `json:"reason"`
+}
+
+func (UserReinstated) EventType() string { return EventUserReinstated }
+
+// UsersExportFilter is the roster filter an export ran with
+type UsersExportFilter struct {
+	UserName    string `json:"user_name"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	UserStatus  string `json:"user_status"`
+	ShowDeleted bool   `json:"show_deleted"`
+}
+
+type UsersExported struct {
+	Format string            `json:"format"`
+	Rows   int               `json:"rows"`
+	Status string            `json:"status"`
+	Filter UsersExportFilter `json:"filter"`
+}
+
+func (UsersExported) EventType() string { return EventUsersExported }
+
+// LoginAttempted is a password login, Status is Success or Failed
+type LoginAttempted struct {
+	Email  string `json:"email"`
+	Status string `json:"status"`
+}
+
+func (LoginAttempted) EventType() string { return EventAuthLogin }
+
+// auditEventTypes keeps audit log names that predate the event bus stable
+var auditEventTypes = map[string]string{
+	EventUsersExported: "USER_EXPORT",
+	EventAuthLogin:     "USER_LOGIN",
+}
+
+// AuditEventType names the audit log entry for an event type, user.suspended is USER_SUSPENDED
+func AuditEventType(eventType string) string {
+	if name, ok := auditEventTypes[eventType]; ok {
+		return name
+	}
+	return strings.ToUpper(strings.NewReplacer(".", "_").Replace(eventType))
+}
+
+// AuditFromEvent turns an event into its audit log entry, the correlation id is kept in the payload
+func AuditFromEvent(e Event) (Audit, error) {
+	payload := map[string]any{}
+	if len(e.Data) > 0 {
+		if err := json.Unmarshal(e.Data, &payload); err != nil {
+			return Audit{}, err
+		}
+	}
+	if e.CorrelationID != "" {
+		payload["correlation_id"] = e.CorrelationID
+	}
+
+	return Audit{
+		UUID:      e.ID,
+		EventType: AuditEventType(e.Type),
+		ActorID:   e.ActorID,
+		Payload:   payload,
+		CreatedAt: e.OccurredAt,
+	}, nil
+}
@@ -0,0 +1,18 @@
+// Package domain
+// this one holds responses kept for idempotent retries
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotentResponse is the stored outcome of a request made with an Idempotency-Key.
+// Fingerprint identifies the request, a retry with another fingerprint reuses the key illegally.
+type IdempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+// Package domain
+// This one holds the lock errors
+package domain
+
+import "errors"
+
+// ErrLockLost means a lock expired or was taken over by another holder, work guarded by it must stop
+var ErrLockLost = errors.New("lock lost")
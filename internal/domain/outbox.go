@@ -0,0 +1,18 @@
+// Package domain
+// this one holds the transactional outbox message
+package domain
+
+import "time"
+
+// OutboxMessage is an event waiting in the database to be relayed to the broker
+type OutboxMessage struct {
+	ID            int64      `db:"id"`
+	UUID          string     `db:"uuid"`
+	Subject       string     `db:"subject"`
+	Payload       []byte     `db:"payload"`
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	LastError     *string    `db:"last_error"`
+	CreatedAt     time.Time  `db:"created_at"`
+	SentAt        *time.Time `db:"sent_at"`
+}
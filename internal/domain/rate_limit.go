@@ -0,0 +1,35 @@
+// Package domain
+// This one holds the rate limit policy and decision
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimit allows Limit requests per Period, a client that was idle may spend all of them at once
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Interval is the steady spacing between requests, one request is earned back per interval
+func (l RateLimit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Limit)
+}
+
+// Policy is the RateLimit-Policy header value, 10 per minute is "10;w=60"
+func (l RateLimit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Limit, int(l.Period.Seconds()))
+}
+
+// RateLimitResult is the decision for one request
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed, zero when this one was
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full limit is available again
+	ResetAfter time.Duration
+}
@@ -0,0 +1,34 @@
+// Package domain
+// this one holds the user suspension domain
+package domain
+
+import "time"
+
+// Suspension is one entry of a user's suspension history
+type Suspension struct {
+	ID          int        `db:"id"`
+	UUID        string     `db:"uuid"`
+	UserID      string     `db:"user_id"`
+	SuspendedBy string     `db:"suspended_by"`
+	Reason      string     `db:"reason"`
+	SuspendedAt time.Time  `db:"suspended_at"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	LiftedAt    *time.Time `db:"lifted_at"`
+	LiftedBy    *string    `db:"lifted_by"`
+	LiftReason  *string    `db:"lift_reason"`
+}
+
+// SuspendUser is the command to suspend a user, a nil Until means indefinitely
+type SuspendUser struct {
+	UserID  string
+	ActorID string
+	Reason  string
+	Until   *time.Time
+}
+
+// UnsuspendUser is the command to lift a user's suspension early
+type UnsuspendUser struct {
+	UserID  string
+	ActorID string
+	Reason  string
+}
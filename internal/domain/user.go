@@ -5,18 +5,19 @@ package domain
 import "time"
 
 type User struct {
-	ID         int        `db:"id"`
-	UUID       string     `db:"uuid"`
-	UserName   string     `db:"user_name"`
-	Email      string     `db:"email"`
-	Phone      string     `db:"phone"`
-	Password   string     `db:"password"`
-	OTP        *string    `db:"otp"`
-	UserStatus string     `db:"user_status"`
-	UserRole   string     `db:"user_role"`
-	CreatedAt  time.Time  `db:"created_at"`
-	UpdatedAt  time.Time  `db:"updated_at"`
-	DeletedAt  *time.Time `db:"deleted_at"`
+	ID                 int        `db:"id"`
+	UUID               string     `db:"uuid"`
+	UserName           string     `db:"user_name"`
+	Email              string     `db:"email"`
+	Phone              string     `db:"phone"`
+	Password           string     `db:"password"`
+	OTP                *string    `db:"otp"`
+	UserStatus         string     `db:"user_status"`
+	UserRole           string     `db:"user_role"`
+	StatusBeforeDelete *string    `db:"status_before_delete"`
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at"`
+	DeletedAt          *time.Time `db:"deleted_at"`
 }
 
 type UserFilter struct {
@@ -36,3 +37,27 @@ type UserUpdate struct {
 	Phone    *string
 	Status   *string
 }
+
+// UserExport describes a roster export request
+type UserExport struct {
+	ActorID string
+	Format  string
+	Filter  UserFilter
+}
+
+// TrashPurge asks to hard delete users trashed before a cutoff
+type TrashPurge struct {
+	ActorID   string // empty for the scheduled job
+	Retention time.Duration
+	DryRun    bool
+	Limit     int
+}
+
+// PurgeReport tells which trashed users were (or in a dry run would be) hard deleted
+type PurgeReport struct {
+	Cutoff     time.Time
+	DryRun     bool
+	Candidates []*User
+	Purged     int
+	Failed     int
+}
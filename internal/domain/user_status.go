@@ -0,0 +1,64 @@
+// Package domain
+// this one holds the user status state machine
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	StatusActive    = "active"
+	StatusInactive  = "inactive"
+	StatusSuspended = "suspended"
+)
+
+// userStatusTransitions lists, for every status, the statuses a user may move to next.
+// Soft deletion is not a status change, it is tracked by deleted_at and StatusBeforeDelete.
+var userStatusTransitions = map[string][]string{
+	StatusActive:    {StatusInactive, StatusSuspended},
+	StatusInactive:  {StatusActive},
+	StatusSuspended: {StatusActive, StatusInactive},
+}
+
+// AllowedStatusTransitions returns the statuses reachable from the given one
+func AllowedStatusTransitions(from string) []string {
+	return userStatusTransitions[from]
+}
+
+// CheckStatusTransition returns a validation AppError if a user may not move from one status to another.
+// Staying in the same status is always allowed.
+func CheckStatusTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	allowed := userStatusTransitions[from]
+	for _, s := range allowed {
+		if s == to {
+			return nil
+		}
+	}
+
+	targets := "none"
+	if len(allowed) > 0 {
+		targets = strings.Join(allowed, ", ")
+	}
+
+	return &AppError{
+		Code:    CodeValidation,
+		Message: fmt.Sprintf("Status can not change from %s to %s, allowed: %s", from, to, targets),
+		Field:   "status",
+		Errors: []ErrorItem{
+			{Field: "status", Message: "allowed: " + targets},
+		},
+	}
+}
+
+// RestoreStatus is the status a trashed user comes back with
+func (u *User) RestoreStatus() string {
+	if u.StatusBeforeDelete != nil && *u.StatusBeforeDelete != "" {
+		return *u.StatusBeforeDelete
+	}
+	return StatusActive
+}
@@ -0,0 +1,75 @@
+// Package domain
+// this one holds outgoing webhook subscriptions and their delivery log
+package domain
+
+import "time"
+
+// Webhook is a partner endpoint subscribed to domain events.
+// EventTypes are patterns matched with MatchEventType, e.g. "user.suspended" or "user.*".
+type Webhook struct {
+	ID          int64
+	UUID        string
+	URL         string
+	EventTypes  []string
+	Secret      string
+	Description string
+	Active      bool
+	CreatedBy   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Wants reports whether the webhook is subscribed to eventType
+func (w *Webhook) Wants(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, p := range w.EventTypes {
+		if MatchEventType(p, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookUpdate is a partial update, nil fields are left alone
+type WebhookUpdate struct {
+	UUID        string
+	URL         *string
+	EventTypes  []string
+	Secret      *string
+	Description *string
+	Active      *bool
+}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, retried until it succeeds or runs out of attempts
+type WebhookDelivery struct {
+	ID             int64      `db:"id"`
+	UUID           string     `db:"uuid"`
+	WebhookID      string     `db:"webhook_id"`
+	EventID        string     `db:"event_id"`
+	EventType      string     `db:"event_type"`
+	Payload        []byte     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	LastStatusCode *int       `db:"last_status_code"`
+	LastError      *string    `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+}
+
+// WebhookDeliveryFilter pages through the delivery log of a webhook newest first
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    string
+	Limit     int
+	Offset    int
+}
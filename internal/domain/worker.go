@@ -0,0 +1,23 @@
+// Package domain
+// this one describes the state of background workers
+package domain
+
+import "time"
+
+type WorkerState string
+
+const (
+	WorkerRunning    WorkerState = "running"
+	WorkerRestarting WorkerState = "restarting"
+	WorkerDraining   WorkerState = "draining"
+	WorkerStopped    WorkerState = "stopped"
+)
+
+// WorkerStatus is what the supervisor knows about one background worker
+type WorkerStatus struct {
+	Name      string
+	State     WorkerState
+	Restarts  int
+	LastError string
+	StartedAt time.Time
+}
@@ -0,0 +1,220 @@
+// Package cache
+// This one puts a read-through cache in front of the user repository
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"golang.org/x/sync/singleflight"
+)
+
+// UserRepo caches active users by uuid, with an email to uuid index next to them.
+// Every write that goes through it bumps the user's generation once committed, an entry only
+// counts while it carries the current generation, so a load that read the row before the write
+// can't put it back afterwards. Writes that bypass it show up once the ttl runs out.
+// Reads inside a transaction skip the cache, they have to see the transaction's own writes and
+// must not publish uncommitted rows to other readers. Entries leave out the password hash and
+// otp, reads that need them ask with domain.WithSecrets and go to the database.
+type UserRepo struct {
+	ports.UserRepository
+	cache  ports.CacheRepo
+	tx     ports.TxManager
+	ttl    time.Duration
+	flight singleflight.Group
+}
+
+// entry is what the cache holds for a user
+type entry struct {
+	User *domain.User `json:"user"`
+	Gen  int64        `json:"gen"`
+}
+
+// NewUserRepo wraps repo, methods it doesn't cache go straight to repo
+func NewUserRepo(repo ports.UserRepository, cache ports.CacheRepo, tx ports.TxManager, ttl time.Duration) *UserRepo {
+	return &UserRepo{
+		UserRepository: repo,
+		cache:          cache,
+		tx:             tx,
+		ttl:            ttl,
+	}
+}
+
+func userKey(id string) string     { return "user:" + id }
+func genKey(id string) string      { return "user:gen:" + id }
+func emailKey(email string) string { return "user:email:" + email }
+
+func (r *UserRepo) ReadOne(ctx context.Context, id string) (*domain.User, error) {
+	if r.tx.InTx(ctx) || domain.SecretsWanted(ctx) {
+		return r.UserRepository.ReadOne(ctx, id)
+	}
+	if u := r.cached(ctx, id); u != nil {
+		return u, nil
+	}
+	return r.load(ctx, id)
+}
+
+// ReadByEmail() follows the index to the user entry. The index can outlive an email change,
+// so the entry only counts when it still carries the email asked for.
+func (r *UserRepo) ReadByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if r.tx.InTx(ctx) || domain.SecretsWanted(ctx) {
+		return r.UserRepository.ReadByEmail(ctx, email)
+	}
+
+	var id string
+	found, err := r.cache.Get(ctx, emailKey(email), &id)
+	if err != nil {
+		slog.Warn("User cache read failed", "key", emailKey(email), "error", err)
+	}
+	if found {
+		if u, err := r.ReadOne(ctx, id); err == nil && u.Email == email {
+			return u, nil
+		}
+	}
+
+	// Only the index is written here. The generation has to be read before the row,
+	// which needs the uuid, so the entry is left to the ReadOne above on the next call.
+	u, err := r.UserRepository.ReadByEmail(domain.WithPrimaryReads(ctx), email)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.cache.Set(ctx, emailKey(email), u.UUID, r.ttl); err != nil {
+		slog.Warn("User cache write failed", "key", emailKey(email), "error", err)
+	}
+	return withoutSecrets(u), nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, updates domain.UserUpdate) error {
+	defer r.invalidate(ctx, updates.UUID)
+	return r.UserRepository.Update(ctx, updates)
+}
+
+func (r *UserRepo) SoftDelete(ctx context.Context, id string) error {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.SoftDelete(ctx, id)
+}
+
+func (r *UserRepo) Restore(ctx context.Context, id string, status string) error {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.Restore(ctx, id, status)
+}
+
+func (r *UserRepo) Prune(ctx context.Context, id string) error {
+	defer r.invalidate(ctx, id)
+	return r.UserRepository.Prune(ctx, id)
+}
+
+// cached returns the user stored under id, nil on a miss, a stale generation or when
+// the cache can't be read
+func (r *UserRepo) cached(ctx context.Context, id string) *domain.User {
+	var e entry
+	found, err := r.cache.Get(ctx, userKey(id), &e)
+	if err != nil {
+		slog.Warn("User cache read failed", "key", userKey(id), "error", err)
+		return nil
+	}
+	if !found || e.User == nil {
+		return nil
+	}
+
+	var gen int64
+	found, err = r.cache.Get(ctx, genKey(id), &gen)
+	if err != nil {
+		slog.Warn("User cache read failed", "key", genKey(id), "error", err)
+		return nil
+	}
+	if !found || gen != e.Gen {
+		return nil
+	}
+	return e.User
+}
+
+// load reads through to the database once per user, concurrent misses for the same
+// user wait for that one query instead of stampeding the database
+func (r *UserRepo) load(ctx context.Context, id string) (*domain.User, error) {
+	v, err, _ := r.flight.Do(id, func() (interface{}, error) {
+		// The callers share the result, one of them going away mustn't fail the others.
+		// Entries are filled from the primary, a lagging replica would put back the row an
+		// invalidation just dropped and keep it for the whole ttl.
+		ctx := domain.WithPrimaryReads(context.WithoutCancel(ctx))
+
+		// Read before the row, a write committing in between bumps it and strands this entry
+		gen, genOK := r.generation(ctx, id)
+
+		u, err := r.UserRepository.ReadOne(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		u = withoutSecrets(u)
+
+		if !genOK {
+			return u, nil
+		}
+		if err := r.cache.Set(ctx, userKey(id), entry{User: u, Gen: gen}, r.ttl); err != nil {
+			slog.Warn("User cache write failed", "key", userKey(id), "error", err)
+			return u, nil
+		}
+		if err := r.cache.Set(ctx, emailKey(u.Email), u.UUID, r.ttl); err != nil {
+			slog.Warn("User cache write failed", "key", emailKey(u.Email), "error", err)
+		}
+		return u, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets its own copy to modify
+	u := *v.(*domain.User)
+	return &u, nil
+}
+
+// generation returns the user's current generation, starting one when there is none.
+// A fresh one starts from the clock so it can't match an entry from before it was lost.
+func (r *UserRepo) generation(ctx context.Context, id string) (int64, bool) {
+	var gen int64
+	found, err := r.cache.Get(ctx, genKey(id), &gen)
+	if err == nil && !found {
+		gen = time.Now().UnixNano()
+		var set bool
+		if set, err = r.cache.SetNX(ctx, genKey(id), gen, r.genTTL()); err == nil && !set {
+			found, err = r.cache.Get(ctx, genKey(id), &gen)
+		}
+	}
+	if err != nil {
+		slog.Warn("User cache generation read failed", "key", genKey(id), "error", err)
+		return 0, false
+	}
+	return gen, true
+}
+
+// genTTL outlives the entries. A generation that expires anyway only turns its entries into misses.
+func (r *UserRepo) genTTL() time.Duration { return 2 * r.ttl }
+
+// invalidate bumps the user's generation after a write and drops the entry, its email index
+// dies with it since an index whose entry is missing counts as a miss. Inside a transaction it
+// waits for the commit, bumping earlier would let a concurrent read cache the row as it was
+// before the write. Outside one it runs even when the write failed, a dropped entry costs one
+// query while a stale one could keep a suspended user logged in.
+func (r *UserRepo) invalidate(ctx context.Context, id string) {
+	ctx = context.WithoutCancel(ctx)
+	r.tx.AfterCommit(ctx, func() {
+		if _, err := r.cache.Incr(ctx, genKey(id)); err != nil {
+			slog.Error("User cache invalidation failed, entry stays stale until it expires", "key", genKey(id), "ttl", r.ttl, "error", err)
+		} else if _, err := r.cache.Expire(ctx, genKey(id), r.genTTL()); err != nil {
+			slog.Warn("User cache generation ttl failed", "key", genKey(id), "error", err)
+		}
+		if err := r.cache.Delete(ctx, userKey(id)); err != nil {
+			slog.Warn("User cache delete failed", "key", userKey(id), "error", err)
+		}
+	})
+}
+
+// withoutSecrets copies u without the password hash and otp
+func withoutSecrets(u *domain.User) *domain.User {
+	c := *u
+	c.Password, c.OTP = "", nil
+	return &c
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/memory"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// noTx runs everything outside a transaction
+type noTx struct{}
+
+func (noTx) WithinTx(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }
+func (noTx) AfterCommit(ctx context.Context, fn func())                         { fn() }
+func (noTx) InTx(ctx context.Context) bool                                      { return false }
+
+// stubUsers serves one user, onRead runs after the row was read and before it is returned
+type stubUsers struct {
+	ports.UserRepository
+	user   domain.User
+	reads  int
+	onRead func()
+}
+
+func (s *stubUsers) ReadOne(ctx context.Context, id string) (*domain.User, error) {
+	s.reads++
+	u := s.user
+	if s.onRead != nil {
+		s.onRead()
+	}
+	return &u, nil
+}
+
+func (s *stubUsers) ReadByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return s.ReadOne(ctx, s.user.UUID)
+}
+
+func (s *stubUsers) Update(ctx context.Context, updates domain.UserUpdate) error {
+	s.user.UserStatus = *updates.Status
+	return nil
+}
+
+func newTestRepo() (*UserRepo, *stubUsers) {
+	otp := "123456"
+	users := &stubUsers{user: domain.User{UUID: "u1", Email: "a@example.com", Password: "hash", OTP: &otp, UserStatus: domain.StatusActive}}
+	return NewUserRepo(users, memory.NewCache(0), noTx{}, time.Minute), users
+}
+
+func TestUserRepoKeepsNoSecrets(t *testing.T) {
+	ctx := context.Background()
+	repo, users := newTestRepo()
+
+	for range 2 {
+		u, err := repo.ReadOne(ctx, "u1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Password != "" || u.OTP != nil {
+			t.Fatalf("cached read returned secrets: %+v", u)
+		}
+	}
+	if users.reads != 1 {
+		t.Fatalf("reads %d, want the second one served from the cache", users.reads)
+	}
+
+	u, err := repo.ReadByEmail(domain.WithSecrets(ctx), "a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Password != "hash" || users.reads != 2 {
+		t.Fatalf("read with secrets: password %q after %d reads", u.Password, users.reads)
+	}
+}
+
+func TestUserRepoDropsLoadsRacingAWrite(t *testing.T) {
+	ctx := context.Background()
+	repo, users := newTestRepo()
+
+	// The write commits and invalidates while the load holds the old row
+	suspended := domain.StatusSuspended
+	users.onRead = func() {
+		users.onRead = nil
+		if err := repo.Update(ctx, domain.UserUpdate{UUID: "u1", Status: &suspended}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := repo.ReadOne(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UserStatus != domain.StatusActive {
+		t.Fatalf("racing load returned %q", u.UserStatus)
+	}
+
+	u, err = repo.ReadOne(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UserStatus != domain.StatusSuspended {
+		t.Fatalf("stale entry served after the write: %q", u.UserStatus)
+	}
+}
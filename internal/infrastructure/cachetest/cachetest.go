@@ -0,0 +1,178 @@
+// Package cachetest
+// This one holds the CacheRepo behavior every cache backend has to show
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// RevokedKey is a key under the revocation prefix. Backends are opened so it is pinned.
+const RevokedKey = "blacklist:refresh:token"
+
+// Backend opens a fresh cache for one test. Advance moves the backend's clock forward,
+// MaxEntries is the eviction limit it was opened with, 0 if it doesn't evict.
+type Backend struct {
+	Open       func(t *testing.T) ports.CacheRepo
+	Advance    func(t *testing.T, d time.Duration)
+	MaxEntries int
+}
+
+// Run runs every case against the backend
+func Run(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	t.Run("set and get round trip", func(t *testing.T) {
+		c := b.Open(t)
+		type value struct {
+			Name  string
+			Count int
+		}
+		if err := c.Set(ctx, "k", value{"a", 2}, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		var got value
+		found, err := c.Get(ctx, "k", &got)
+		if err != nil || !found {
+			t.Fatalf("get: found %v err %v", found, err)
+		}
+		if got != (value{"a", 2}) {
+			t.Fatalf("got %+v", got)
+		}
+
+		found, err = c.Get(ctx, "missing", &got)
+		if err != nil || found {
+			t.Fatalf("missing key: found %v err %v", found, err)
+		}
+	})
+
+	t.Run("delete and exists", func(t *testing.T) {
+		c := b.Open(t)
+		for _, k := range []string{"a", "b"} {
+			if err := c.Set(ctx, k, 1, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := c.Delete(ctx, "a", "b", "never-set"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range []string{"a", "b"} {
+			if ok, err := c.Exists(ctx, k); err != nil || ok {
+				t.Fatalf("%s after delete: exists %v err %v", k, ok, err)
+			}
+		}
+	})
+
+	t.Run("setnx only sets once", func(t *testing.T) {
+		c := b.Open(t)
+		ok, err := c.SetNX(ctx, "lock", "first", time.Minute)
+		if err != nil || !ok {
+			t.Fatalf("first setnx: %v %v", ok, err)
+		}
+		ok, err = c.SetNX(ctx, "lock", "second", time.Minute)
+		if err != nil || ok {
+			t.Fatalf("second setnx: %v %v", ok, err)
+		}
+
+		var got string
+		if _, err := c.Get(ctx, "lock", &got); err != nil || got != "first" {
+			t.Fatalf("value %q err %v", got, err)
+		}
+	})
+
+	t.Run("incr counts and keeps the ttl", func(t *testing.T) {
+		c := b.Open(t)
+		for want := int64(1); want <= 3; want++ {
+			n, err := c.Incr(ctx, "n")
+			if err != nil || n != want {
+				t.Fatalf("incr: %d err %v, want %d", n, err, want)
+			}
+		}
+
+		var got int64
+		if _, err := c.Get(ctx, "n", &got); err != nil || got != 3 {
+			t.Fatalf("get counter: %d err %v", got, err)
+		}
+
+		if ok, err := c.Expire(ctx, "n", time.Second); err != nil || !ok {
+			t.Fatalf("expire: %v %v", ok, err)
+		}
+		if _, err := c.Incr(ctx, "n"); err != nil {
+			t.Fatal(err)
+		}
+		b.Advance(t, 2*time.Second)
+		if ok, _ := c.Exists(ctx, "n"); ok {
+			t.Fatal("incr dropped the ttl")
+		}
+
+		if err := c.Set(ctx, "word", "abc", 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Incr(ctx, "word"); err == nil {
+			t.Fatal("incr of a string succeeded")
+		}
+	})
+
+	t.Run("ttl expires keys", func(t *testing.T) {
+		c := b.Open(t)
+		if err := c.Set(ctx, "short", "v", time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Set(ctx, "forever", "v", 0); err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := c.Exists(ctx, "short"); !ok {
+			t.Fatal("expired early")
+		}
+
+		b.Advance(t, 2*time.Second)
+		var got string
+		if found, err := c.Get(ctx, "short", &got); err != nil || found {
+			t.Fatalf("after ttl: found %v err %v", found, err)
+		}
+		if ok, _ := c.Exists(ctx, "forever"); !ok {
+			t.Fatal("key without ttl expired")
+		}
+
+		if ok, err := c.Expire(ctx, "missing", time.Minute); err != nil || ok {
+			t.Fatalf("expire of a missing key: %v %v", ok, err)
+		}
+		if ok, err := c.Expire(ctx, "forever", 0); err != nil || !ok {
+			t.Fatalf("expire to zero: %v %v", ok, err)
+		}
+		if ok, _ := c.Exists(ctx, "forever"); ok {
+			t.Fatal("expire to zero kept the key")
+		}
+	})
+
+	t.Run("revocation survives a full cache", func(t *testing.T) {
+		c := b.Open(t)
+		if err := c.Set(ctx, RevokedKey, "revoked", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		fill := 4*b.MaxEntries + 16
+		for i := range fill {
+			if err := c.Set(ctx, fmt.Sprintf("filler:%d", i), i, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ok, err := c.Exists(ctx, RevokedKey); err != nil || !ok {
+			t.Fatalf("revocation gone after %d writes: exists %v err %v", fill, ok, err)
+		}
+
+		// Still expires on its own
+		b.Advance(t, 2*time.Hour)
+		if ok, _ := c.Exists(ctx, RevokedKey); ok {
+			t.Fatal("revocation outlived its ttl")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+// Package memory
+// In-process adapters so the app can run without external brokers
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// AuditConsumer is the name the audit queue subscribes to the event bus under
+const AuditConsumer = "audit"
+
+// AuditQueue is the audit log's subscriber on the memory event bus. Events are buffered
+// and stored in batches from the same process, the buffer is lost if the process dies.
+type AuditQueue struct {
+	repo      ports.AuditRepository
+	ch        chan queuedAudit
+	sync      bool
+	batchSize int
+	dead      *DeadLetters
+	wg        sync.WaitGroup
+
+	stored      atomic.Uint64
+	batches     atomic.Uint64
+	fallbacks   atomic.Uint64
+	lastBatchAt atomic.Int64
+}
+
+// queuedAudit keeps the event next to its audit entry so a failed insert can be dead lettered
+type queuedAudit struct {
+	event domain.Event
+	audit domain.Audit
+}
+
+// NewAuditQueue buffers up to buffer events, Start stores them in batches of up to batchSize.
+// Events that can't be stored end up in dead.
+func NewAuditQueue(repo ports.AuditRepository, dead *DeadLetters, buffer, batchSize int) *AuditQueue {
+	return &AuditQueue{
+		repo:      repo,
+		ch:        make(chan queuedAudit, buffer),
+		batchSize: batchSize,
+		dead:      dead,
+	}
+}
+
+// NewSyncAuditQueue stores every event inside Handle, so tests can assert on the audit log right away
+func NewSyncAuditQueue(repo ports.AuditRepository, dead *DeadLetters) *AuditQueue {
+	return &AuditQueue{repo: repo, sync: true, batchSize: 1, dead: dead}
+}
+
+// Handle() is the queue's ports.EventHandler. It blocks while the buffer is full, so a stalled
+// database slows producers instead of dropping events.
+func (q *AuditQueue) Handle(ctx context.Context, event domain.Event) error {
+	audit, err := domain.AuditFromEvent(event)
+	if err != nil {
+		return err
+	}
+
+	if q.sync {
+		if err := q.repo.Create(ctx, audit); err != nil {
+			return err
+		}
+		q.stored.Add(1)
+		return nil
+	}
+
+	select {
+	case q.ch <- queuedAudit{event: event, audit: audit}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start() stores events until ctx is done, then flushes what is left in the buffer
+func (q *AuditQueue) Start(ctx context.Context) error {
+	if q.sync {
+		<-ctx.Done()
+		return nil
+	}
+
+	q.wg.Add(1)
+	defer q.wg.Done()
+
+	// The flush has to outlive the cancelled context
+	store := context.WithoutCancel(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for len(q.ch) > 0 {
+				q.storeBatch(store, q.collect(<-q.ch))
+			}
+			return nil
+		case first := <-q.ch:
+			q.storeBatch(store, q.collect(first))
+		}
+	}
+}
+
+func (q *AuditQueue) Stop() { q.wg.Wait() }
+
+// collect takes whatever is already buffered, up to batchSize, without waiting for more
+func (q *AuditQueue) collect(first queuedAudit) []queuedAudit {
+	batch := []queuedAudit{first}
+	for len(batch) < q.batchSize {
+		select {
+		case a := <-q.ch:
+			batch = append(batch, a)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// storeBatch writes the batch in one transaction and falls back to one by one on failure.
+// An event that still fails is dead lettered so it can be replayed.
+func (q *AuditQueue) storeBatch(ctx context.Context, batch []queuedAudit) {
+	audits := make([]domain.Audit, len(batch))
+	for i, item := range batch {
+		audits[i] = item.audit
+	}
+
+	err := q.repo.CreateBatch(ctx, audits)
+	if err == nil {
+		q.batches.Add(1)
+		q.stored.Add(uint64(len(batch)))
+		q.lastBatchAt.Store(time.Now().UnixNano())
+		return
+	}
+
+	slog.Warn("Audit batch insert failed, storing one by one", "size", len(batch), "error", err)
+	q.fallbacks.Add(1)
+
+	for _, item := range batch {
+		if err := q.repo.Create(ctx, item.audit); err != nil {
+			slog.Error("Audit insert failed, dead lettering", "audit_id", item.audit.UUID, "error", err)
+			q.dead.add(AuditConsumer, item.event, err.Error())
+			continue
+		}
+		q.stored.Add(1)
+	}
+}
+
+// IngestStats() reports the buffer as lag, the queue is always connected
+func (q *AuditQueue) IngestStats(ctx context.Context) (domain.IngestStats, error) {
+	stats := domain.IngestStats{
+		Workers:         1,
+		BatchSize:       q.batchSize,
+		Connected:       true,
+		Pending:         uint64(len(q.ch)),
+		Stored:          q.stored.Load(),
+		Batches:         q.batches.Load(),
+		FallbackBatches: q.fallbacks.Load(),
+		DeadLettered:    q.dead.Count(AuditConsumer),
+	}
+	if at := q.lastBatchAt.Load(); at > 0 {
+		t := time.Unix(0, at)
+		stats.LastBatchAt = &t
+	}
+	return stats, nil
+}
@@ -0,0 +1,274 @@
+// Package memory
+// This one is the in-process key value cache
+package memory
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// ErrNotInteger is what Incr returns for a value that isn't an integer, like redis does
+var ErrNotInteger = errors.New("value is not an integer or out of range")
+
+// Cache behaves like the redis CacheRepo within one process. Values are kept as JSON so Get
+// decodes exactly what redis would return. Past maxEntries the least recently used key is evicted,
+// except keys under a pinned prefix. Those have no other copy, like refresh token revocations,
+// so they only leave by expiring or being deleted and don't count against the limit.
+type Cache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List // front is the most recently used
+	pinned     *list.List
+	prefixes   []string
+	maxEntries int
+	now        func() time.Time
+}
+
+type cacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero never expires
+	pinned    bool
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewCache holds up to maxEntries keys, 0 means no limit. Keys starting with one of the
+// pinned prefixes are never evicted.
+func NewCache(maxEntries int, pinned ...string) *Cache {
+	return &Cache{
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		pinned:     list.New(),
+		prefixes:   pinned,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Set() with a zero ttl keeps the value until it is deleted or evicted
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(key, data, c.expiry(ttl))
+	return nil
+}
+
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	c.mu.Lock()
+	e := c.lookup(key)
+	var data []byte
+	if e != nil {
+		data = e.value
+	}
+	c.mu.Unlock()
+
+	if e == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(key) != nil, nil
+}
+
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookup(key) != nil {
+		return false, nil
+	}
+	c.put(key, data, c.expiry(ttl))
+	return true, nil
+}
+
+// Incr() keeps the ttl of an existing key like redis INCR does
+func (c *Cache) Incr(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	var expiresAt time.Time
+	if e := c.lookup(key); e != nil {
+		var err error
+		if n, err = strconv.ParseInt(string(e.value), 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+		expiresAt = e.expiresAt
+	}
+
+	n++
+	c.put(key, []byte(strconv.FormatInt(n, 10)), expiresAt)
+	return n, nil
+}
+
+// Expire() with a ttl of zero or less deletes the key, like redis EXPIRE does
+func (c *Cache) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return false, nil
+	}
+	if ttl <= 0 {
+		c.remove(c.entries[key])
+		return true, nil
+	}
+	e.expiresAt = c.now().Add(ttl)
+	return true, nil
+}
+
+// Janitor returns a worker that sweeps expired keys every interval. Expired keys are never
+// returned even without it, it only gives back the memory of keys nobody reads anymore.
+func (c *Cache) Janitor(interval time.Duration) ports.BackgroundWorker {
+	return &cacheJanitor{cache: c, interval: interval}
+}
+
+// lookup returns the live entry under key and marks it used, dropping it if it expired.
+// The caller holds the lock.
+func (c *Cache) lookup(key string) *cacheEntry {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*cacheEntry)
+	if e.expired(c.now()) {
+		c.remove(el)
+		return nil
+	}
+	if !e.pinned {
+		c.lru.MoveToFront(el)
+	}
+	return e
+}
+
+// put stores the entry and evicts past the limit, the caller holds the lock
+func (c *Cache) put(key string, value []byte, expiresAt time.Time) {
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		e.value, e.expiresAt = value, expiresAt
+		if !e.pinned {
+			c.lru.MoveToFront(el)
+		}
+		return
+	}
+
+	e := &cacheEntry{key: key, value: value, expiresAt: expiresAt, pinned: c.isPinned(key)}
+	if e.pinned {
+		c.entries[key] = c.pinned.PushFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) isPinned(key string) bool {
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) remove(el *list.Element) {
+	e := el.Value.(*cacheEntry)
+	if e.pinned {
+		c.pinned.Remove(el)
+	} else {
+		c.lru.Remove(el)
+	}
+	delete(c.entries, e.key)
+}
+
+// sweep drops every expired entry
+func (c *Cache) sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for _, l := range []*list.List{c.lru, c.pinned} {
+		for el := l.Front(); el != nil; {
+			next := el.Next()
+			if el.Value.(*cacheEntry).expired(now) {
+				c.remove(el)
+			}
+			el = next
+		}
+	}
+}
+
+func (c *Cache) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return c.now().Add(ttl)
+}
+
+type cacheJanitor struct {
+	cache    *Cache
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+func (j *cacheJanitor) Start(ctx context.Context) error {
+	j.wg.Add(1)
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			j.cache.sweep()
+		}
+	}
+}
+
+func (j *cacheJanitor) Stop() { j.wg.Wait() }
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/cachetest"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// fakeClock hands the cache a time the test moves by hand
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newTestCache(maxEntries int) (*Cache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	c := NewCache(maxEntries, "blacklist:")
+	c.now = clock.Now
+	return c, clock
+}
+
+func TestCacheBehavior(t *testing.T) {
+	var clock *fakeClock
+	cachetest.Run(t, cachetest.Backend{
+		Open: func(t *testing.T) ports.CacheRepo {
+			var c *Cache
+			c, clock = newTestCache(8)
+			return c
+		},
+		Advance:    func(t *testing.T, d time.Duration) { clock.Advance(d) },
+		MaxEntries: 8,
+	})
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestCache(3)
+
+	for i := range 3 {
+		c.Set(ctx, fmt.Sprintf("k%d", i), i, 0)
+	}
+	// Reading k0 makes k1 the least recently used
+	var v int
+	c.Get(ctx, "k0", &v)
+	c.Set(ctx, "k3", 3, 0)
+
+	for key, want := range map[string]bool{"k0": true, "k1": false, "k2": true, "k3": true} {
+		if ok, _ := c.Exists(ctx, key); ok != want {
+			t.Errorf("%s exists %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestCachePinnedKeysDontCountAgainstTheLimit(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestCache(2)
+
+	for i := range 5 {
+		c.Set(ctx, fmt.Sprintf("blacklist:refresh:%d", i), "revoked", time.Minute)
+	}
+	c.Set(ctx, "a", 1, 0)
+	c.Set(ctx, "b", 2, 0)
+
+	for _, key := range []string{"a", "b", "blacklist:refresh:0", "blacklist:refresh:4"} {
+		if ok, _ := c.Exists(ctx, key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+
+	clock.Advance(2 * time.Minute)
+	c.sweep()
+	if got := c.pinned.Len(); got != 0 {
+		t.Fatalf("sweep left %d expired pinned keys", got)
+	}
+	if got := len(c.entries); got != 2 {
+		t.Fatalf("entries %d after sweep, want 2", got)
+	}
+}
@@ -0,0 +1,265 @@
+// Package memory
+// This one is the in-process domain event bus
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// EventBus fans events out to subscribers over a buffered channel each.
+// Events still buffered are lost if the process dies, use the NATS driver where that matters.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []*subscription
+	buffer int
+	sync   bool
+	dead   *DeadLetters
+}
+
+// NewEventBus buffers up to buffer events per subscriber
+func NewEventBus(buffer int) *EventBus {
+	b := &EventBus{buffer: buffer}
+	b.dead = &DeadLetters{bus: b}
+	return b
+}
+
+// NewSyncEventBus runs every handler inside Publish, so tests can assert on their effects right away
+func NewSyncEventBus() *EventBus {
+	b := &EventBus{sync: true}
+	b.dead = &DeadLetters{bus: b}
+	return b
+}
+
+// Publish() blocks while a subscriber's buffer is full, so a stalled subscriber slows producers
+// instead of dropping events. A failing handler dead letters the event, it never fails the publish.
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs {
+		if !s.matches(event.Type) {
+			continue
+		}
+		if err := s.enqueue(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe() registers the handler, name identifies the subscriber's dead letters
+func (b *EventBus) Subscribe(name string, patterns []string, handler ports.EventHandler) ports.BackgroundWorker {
+	s := &subscription{
+		name:     name,
+		patterns: patterns,
+		handler:  handler,
+		dead:     b.dead,
+	}
+	if !b.sync {
+		s.ch = make(chan domain.Event, b.buffer)
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, s)
+	b.mu.Unlock()
+
+	return s
+}
+
+// DeadLetters() returns the events subscribers could not handle
+func (b *EventBus) DeadLetters() *DeadLetters {
+	return b.dead
+}
+
+// redeliver hands the event to the named subscriber only
+func (b *EventBus) redeliver(ctx context.Context, consumer string, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs {
+		if s.name == consumer {
+			return s.enqueue(ctx, event)
+		}
+	}
+	return fmt.Errorf("no subscriber named %q", consumer)
+}
+
+type subscription struct {
+	name     string
+	patterns []string
+	handler  ports.EventHandler
+	ch       chan domain.Event
+	dead     *DeadLetters
+	wg       sync.WaitGroup
+}
+
+func (s *subscription) matches(eventType string) bool {
+	for _, p := range s.patterns {
+		if domain.MatchEventType(p, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subscription) enqueue(ctx context.Context, event domain.Event) error {
+	if s.ch == nil {
+		s.deliver(ctx, event)
+		return nil
+	}
+
+	select {
+	case s.ch <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *subscription) deliver(ctx context.Context, event domain.Event) {
+	if err := s.handler(ctx, event); err != nil {
+		slog.Error("Event handler failed, dead lettering", "consumer", s.name, "event_id", event.ID, "type", event.Type, "error", err)
+		s.dead.add(s.name, event, err.Error())
+	}
+}
+
+// Start() delivers events until ctx is done, then drains what is left in the buffer
+func (s *subscription) Start(ctx context.Context) error {
+	if s.ch == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	// The drain has to outlive the cancelled context
+	deliver := context.WithoutCancel(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for len(s.ch) > 0 {
+				s.deliver(deliver, <-s.ch)
+			}
+			return nil
+		case event := <-s.ch:
+			s.deliver(deliver, event)
+		}
+	}
+}
+
+func (s *subscription) Stop() { s.wg.Wait() }
+
+// maxDeadLetters bounds memory, the oldest dead letters are dropped past it
+const maxDeadLetters = 1000
+
+// DeadLetters keeps failed events in memory for inspection and replay
+type DeadLetters struct {
+	bus    *EventBus
+	mu     sync.Mutex
+	seq    uint64
+	list   []*domain.DeadLetter
+	counts map[string]uint64
+}
+
+func (d *DeadLetters) add(consumer string, event domain.Event, reason string) {
+	payload, _ := json.Marshal(event)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq++
+	d.list = append(d.list, &domain.DeadLetter{
+		Seq:        d.seq,
+		Consumer:   consumer,
+		Subject:    event.Subject(),
+		Reason:     reason,
+		Deliveries: 1,
+		FailedAt:   time.Now(),
+		Payload:    payload,
+	})
+	if len(d.list) > maxDeadLetters {
+		d.list = d.list[len(d.list)-maxDeadLetters:]
+	}
+
+	if d.counts == nil {
+		d.counts = map[string]uint64{}
+	}
+	d.counts[consumer]++
+}
+
+// Count() is how many events consumer dead lettered since startup
+func (d *DeadLetters) Count(consumer string) uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.counts[consumer]
+}
+
+// List() reads dead letters after filter.AfterSeq, oldest first
+func (d *DeadLetters) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	list := []*domain.DeadLetter{}
+	for _, dl := range d.list {
+		if len(list) == filter.Limit {
+			break
+		}
+		if dl.Seq > filter.AfterSeq {
+			list = append(list, dl)
+		}
+	}
+	return list, nil
+}
+
+// Replay() delivers the event again to the subscriber that failed it and forgets the dead letter
+func (d *DeadLetters) Replay(ctx context.Context, seq uint64) (*domain.DeadLetter, error) {
+	d.mu.Lock()
+	idx := -1
+	for i, dl := range d.list {
+		if dl.Seq == seq {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		d.mu.Unlock()
+		return nil, &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "the requested resource was not found",
+		}
+	}
+	dl := d.list[idx]
+	d.list = append(d.list[:idx], d.list[idx+1:]...)
+	d.mu.Unlock()
+
+	var event domain.Event
+	if err := json.Unmarshal(dl.Payload, &event); err != nil {
+		return nil, err
+	}
+	if err := d.bus.redeliver(ctx, dl.Consumer, event); err != nil {
+		// Put it back in sequence order so the replay can be tried again
+		d.mu.Lock()
+		at := len(d.list)
+		for i, other := range d.list {
+			if other.Seq > dl.Seq {
+				at = i
+				break
+			}
+		}
+		d.list = slices.Insert(d.list, at, dl)
+		d.mu.Unlock()
+		return nil, err
+	}
+
+	return dl, nil
+}
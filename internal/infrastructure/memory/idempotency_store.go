@@ -0,0 +1,58 @@
+// Package memory
+// This one keeps idempotent responses in process
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// IdempotencyStore keeps responses in a Cache, so they are evicted along with everything else in it.
+// Keys are only known to this process, with several replicas use the redis one.
+type IdempotencyStore struct {
+	cache *Cache
+	mu    sync.Mutex
+	locks map[string]time.Time
+}
+
+func NewIdempotencyStore(cache *Cache) *IdempotencyStore {
+	return &IdempotencyStore{cache: cache, locks: map[string]time.Time{}}
+}
+
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*domain.IdempotentResponse, error) {
+	res := &domain.IdempotentResponse{}
+	found, err := s.cache.Get(ctx, key, res)
+	if err != nil || !found {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *IdempotencyStore) Save(ctx context.Context, key string, res domain.IdempotentResponse, ttl time.Duration) error {
+	return s.cache.Set(ctx, key, res, ttl)
+}
+
+// Lock() tracks holders by expiry, unlock only releases a lock that wasn't taken over after expiring
+func (s *IdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if until, held := s.locks[key]; held && now.Before(until) {
+		return nil, false, nil
+	}
+	until := now.Add(ttl)
+	s.locks[key] = until
+
+	unlock := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.locks[key].Equal(until) {
+			delete(s.locks, key)
+		}
+	}
+	return unlock, true, nil
+}
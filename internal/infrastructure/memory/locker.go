@@ -0,0 +1,72 @@
+// Package memory
+// This one implements the Locker port in process
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// Locker only excludes holders within one process, for a single node or tests
+type Locker struct {
+	mu     sync.Mutex
+	held   map[string]*lock
+	fences map[string]uint64
+}
+
+func NewLocker() *Locker {
+	return &Locker{
+		held:   map[string]*lock{},
+		fences: map[string]uint64{},
+	}
+}
+
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (ports.Lock, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if cur, ok := l.held[key]; ok && now.Before(cur.until) {
+		return nil, false, nil
+	}
+
+	l.fences[key]++
+	lk := &lock{locker: l, key: key, token: l.fences[key], until: now.Add(ttl)}
+	l.held[key] = lk
+	return lk, true, nil
+}
+
+type lock struct {
+	locker *Locker
+	key    string
+	token  uint64
+	until  time.Time // guarded by locker.mu
+}
+
+func (l *lock) Token() uint64 { return l.token }
+
+func (l *lock) Renew(ctx context.Context, ttl time.Duration) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	now := time.Now()
+	if l.locker.held[l.key] != l || !now.Before(l.until) {
+		return domain.ErrLockLost
+	}
+	l.until = now.Add(ttl)
+	return nil
+}
+
+func (l *lock) Release(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	if l.locker.held[l.key] == l {
+		delete(l.locker.held, l.key)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package memory
+// This one is the in-process rate limiter
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// rateLimiterSweep is how often keys that are back to a full budget are forgotten
+const rateLimiterSweep = time.Minute
+
+// RateLimiter is GCRA over a map, the same algorithm as the redis one.
+// Limits are per process, so with several replicas each one allows the full limit.
+type RateLimiter struct {
+	mu        sync.Mutex
+	tat       map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		tat: map[string]time.Time{},
+	}
+}
+
+// Allow() tracks the theoretical arrival time of the next request per key. A request is allowed
+// while that time is less than one period ahead, each allowed request pushes it one interval further.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	interval := limit.Interval()
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	tat, ok := l.tat[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-interval * time.Duration(limit.Limit))
+	if now.Before(allowAt) {
+		return domain.RateLimitResult{
+			Limit:      limit.Limit,
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}, nil
+	}
+
+	l.tat[key] = newTAT
+	return domain.RateLimitResult{
+		Allowed:    true,
+		Limit:      limit.Limit,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, nil
+}
+
+// sweep drops keys whose budget has fully refilled, the caller holds the lock
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweep {
+		return
+	}
+	l.lastSweep = now
+
+	for key, tat := range l.tat {
+		if tat.Before(now) {
+			delete(l.tat, key)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package memory
+// This one holds events back until the transaction that raised them commits
+package memory
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// TxPublisher is the in-memory stand-in for the outbox. Inside a transaction events wait for
+// the commit, so a rollback drops them and a retried transaction only delivers its last run.
+type TxPublisher struct {
+	next ports.EventPublisher
+	tx   ports.TxManager
+}
+
+func NewTxPublisher(next ports.EventPublisher, tx ports.TxManager) *TxPublisher {
+	return &TxPublisher{next: next, tx: tx}
+}
+
+// Publish() delivers right away outside a transaction. Once committed the change stands,
+// so a publish failing then is logged rather than returned.
+func (p *TxPublisher) Publish(ctx context.Context, event domain.Event) error {
+	if !p.tx.InTx(ctx) {
+		return p.next.Publish(ctx, event)
+	}
+
+	p.tx.AfterCommit(ctx, func() {
+		if err := p.next.Publish(context.WithoutCancel(ctx), event); err != nil {
+			slog.Error("Event publish after commit failed", "event", event.Type, "event_id", event.ID, "error", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package nats
+// This one follows the event stream live with ephemeral consumers
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/nats-io/nats.go"
+)
+
+type AuditStreamReader struct {
+	js nats.JetStreamContext
+}
+
+func NewAuditStreamReader(js nats.JetStreamContext) *AuditStreamReader {
+	return &AuditStreamReader{js: js}
+}
+
+// Subscribe() creates an ordered consumer, JetStream's ephemeral flow controlled consumer,
+// and deletes it when ctx is done
+func (r *AuditStreamReader) Subscribe(ctx context.Context, start domain.StreamStart) (<-chan domain.StreamedAudit, error) {
+	info, err := r.js.StreamInfo(EventsStream, nats.Context(ctx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	// A work queue stream allows no second consumer and forgets what it delivered
+	if info.Config.Retention != nats.LimitsPolicy {
+		return nil, &domain.AppError{
+			Code:    domain.CodeUnavailable,
+			Message: "live audit stream is unavailable until the event stream is migrated",
+		}
+	}
+
+	opts := []nats.SubOpt{nats.OrderedConsumer()}
+	switch {
+	case start.AfterSeq > 0:
+		opts = append(opts, nats.StartSequence(start.AfterSeq+1))
+	case !start.From.IsZero():
+		opts = append(opts, nats.StartTime(start.From))
+	default:
+		opts = append(opts, nats.DeliverNew())
+	}
+
+	msgs := make(chan *nats.Msg, 64)
+	sub, err := r.js.ChanSubscribe(EventsSubjects, msgs, opts...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	out := make(chan domain.StreamedAudit)
+	go func() {
+		defer close(out)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-msgs:
+				meta, err := msg.Metadata()
+				if err != nil {
+					continue
+				}
+
+				// A replayed dead letter was already shown when it was first published
+				if msg.Header.Get(headerReplayFor) != "" {
+					continue
+				}
+
+				e := domain.StreamedAudit{Seq: meta.Sequence.Stream, PublishedAt: meta.Timestamp}
+				var event domain.Event
+				err = json.Unmarshal(msg.Data, &event)
+				if err == nil {
+					e.Audit, err = domain.AuditFromEvent(event)
+				}
+				if err != nil {
+					slog.Warn("Skipping undecodable event in live stream", "seq", e.Seq, "error", err)
+					continue
+				}
+
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
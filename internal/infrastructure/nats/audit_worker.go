@@ -3,8 +3,10 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
@@ -12,50 +14,222 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
-type auditWorker struct {
-	js   nats.JetStreamContext
-	repo ports.AuditRepository
-	wg   sync.WaitGroup
+// auditConsumer is the durable consumer the audit log is written from
+const auditConsumer = "audit"
+
+// AuditWorker is the audit log's subscriber on the event stream. It is batched,
+// unlike the bus subscribers, so the log keeps up with every event the app raises.
+type AuditWorker struct {
+	js          nats.JetStreamContext
+	repo        ports.AuditRepository
+	maxDeliver  int
+	batchSize   int
+	concurrency int
+	wg          sync.WaitGroup
+
+	// Any live subscription can answer for the shared durable consumer
+	sub   atomic.Pointer[nats.Subscription]
+	stats ingestCounters
 }
 
-func NewAuditWorker(js nats.JetStreamContext, repo ports.AuditRepository) ports.BackgroundWorker {
-	return &auditWorker{js: js, repo: repo}
+// NewAuditWorker returns a worker that stores every event from the stream in the audit log.
+// concurrency fetchers each pull up to batchSize messages and store them in one transaction.
+// A message failing maxDeliver times, or one that can't be decoded, is moved to the dead letter stream.
+func NewAuditWorker(js nats.JetStreamContext, repo ports.AuditRepository, maxDeliver, batchSize, concurrency int) *AuditWorker {
+	return &AuditWorker{
+		js:          js,
+		repo:        repo,
+		maxDeliver:  maxDeliver,
+		batchSize:   batchSize,
+		concurrency: concurrency,
+	}
 }
 
-func (w *auditWorker) Start(ctx context.Context) error {
-	// 'audit-durable' ensures NATS remembers where we left off if we restart
-	sub, err := w.js.PullSubscribe("audit.event", "audit-durable")
-	if err != nil {
-		slog.Error("Error subscbing to event stream", "error", err)
-	}
-
-	w.wg.Add(1)
-	go func() {
-		defer w.wg.Done()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msgs, err := sub.Fetch(1, nats.MaxWait(1*time.Second))
-				if err != nil {
-					continue
+// Start() runs the fetchers until ctx is done. A panicking fetcher stops the others
+// and is reported as an error so the whole worker gets restarted.
+func (w *AuditWorker) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	crashed := make(chan error, w.concurrency)
+	for range w.concurrency {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					crashed <- fmt.Errorf("audit fetcher panic: %v", r)
+					cancel()
 				}
+			}()
+			w.run(ctx)
+		}()
+	}
+
+	w.wg.Wait()
+	close(crashed)
+	return <-crashed
+}
 
-				msg := msgs[0]
-				var e domain.Audit
-				json.Unmarshal(msg.Data, &e)
+func (w *AuditWorker) Stop() { w.wg.Wait() }
 
-				// SAVE to the Partitioned Postgres table
-				if err := w.repo.Create(ctx, e); err != nil {
-					msg.Nak() // Try again later
-					continue
-				}
-				msg.Ack() // Success! Delete from NATS
+func (w *AuditWorker) run(ctx context.Context) {
+	// NATS may not be up yet, keep trying until the stream can be subscribed to
+	sub := w.subscribe(ctx)
+	if sub == nil {
+		return
+	}
+	w.sub.CompareAndSwap(nil, sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			msgs, err := sub.Fetch(w.batchSize, nats.MaxWait(1*time.Second))
+			if err != nil || len(msgs) == 0 {
+				continue
+			}
+
+			// A fetched batch is finished even if shutdown starts meanwhile, so drain doesn't leave it half acked
+			w.handleBatch(context.WithoutCancel(ctx), msgs)
+		}
+	}
+}
+
+// pending is a decoded message waiting to be stored
+type pending struct {
+	msg        *nats.Msg
+	event      domain.Audit
+	deliveries uint64
+}
+
+// handleBatch stores the batch in one transaction and acks it after commit.
+// If the batch fails every message is retried on its own so one bad event can't hold back the rest.
+func (w *AuditWorker) handleBatch(ctx context.Context, msgs []*nats.Msg) {
+	batch := make([]pending, 0, len(msgs))
+	for _, msg := range msgs {
+		if replayedForOther(msg, auditConsumer) {
+			msg.Ack()
+			continue
+		}
+
+		p := pending{msg: msg, deliveries: 1}
+		if meta, err := msg.Metadata(); err == nil {
+			p.deliveries = meta.NumDelivered
+		}
+
+		// A payload that can't be decoded will never succeed, no point retrying it
+		var event domain.Event
+		err := json.Unmarshal(msg.Data, &event)
+		if err == nil {
+			p.event, err = domain.AuditFromEvent(event)
+		}
+		if err != nil {
+			w.deadLetter(msg, "undecodable payload: "+err.Error(), p.deliveries)
+			continue
+		}
+		batch = append(batch, p)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	events := make([]domain.Audit, len(batch))
+	for i, p := range batch {
+		events[i] = p.event
+	}
+
+	// SAVE to the Partitioned Postgres table
+	started := time.Now()
+	if err := w.repo.CreateBatch(ctx, events); err != nil {
+		slog.Warn("Audit batch insert failed, storing one by one", "size", len(batch), "error", err)
+		w.stats.fallbacks.Add(1)
+		for _, p := range batch {
+			w.handle(ctx, p)
+		}
+		return
+	}
+
+	for _, p := range batch {
+		p.msg.Ack() // Success! Delete from NATS
+	}
+	w.stats.batch(len(batch), time.Since(started))
+}
+
+func (w *AuditWorker) handle(ctx context.Context, p pending) {
+	if err := w.repo.Create(ctx, p.event); err != nil {
+		if p.deliveries >= uint64(w.maxDeliver) {
+			w.deadLetter(p.msg, err.Error(), p.deliveries)
+			return
+		}
+
+		delay := backoff(int(p.deliveries))
+		slog.Warn("Audit insert failed, retrying", "audit_id", p.event.UUID, "deliveries", p.deliveries, "retry_in", delay, "error", err)
+		w.stats.retried.Add(1)
+		p.msg.NakWithDelay(delay)
+		return
+	}
+
+	p.msg.Ack()
+	w.stats.stored(1)
+}
+
+// deadLetter parks the message in the DLQ and acks it, if the DLQ is unreachable the message is redelivered
+func (w *AuditWorker) deadLetter(msg *nats.Msg, reason string, deliveries uint64) {
+	if err := deadLetter(w.js, msg, auditConsumer, reason, deliveries); err != nil {
+		slog.Error("Dead lettering audit event failed", "reason", reason, "error", err)
+		msg.NakWithDelay(backoff(int(deliveries)))
+		return
+	}
+
+	slog.Error("Audit event dead lettered", "reason", reason, "deliveries", deliveries)
+	w.stats.deadLettered.Add(1)
+	msg.Ack()
+}
+
+// subscribe retries until it gets a subscription, returns nil once ctx is done
+func (w *AuditWorker) subscribe(ctx context.Context) *nats.Subscription {
+	for {
+		err := EnsureStreams(w.js)
+		if err == nil {
+			// The durable consumer ensures NATS remembers where we left off if we restart
+			var sub *nats.Subscription
+			sub, err = w.js.PullSubscribe(EventsSubjects, auditConsumer, nats.BindStream(EventsStream))
+			if err == nil {
+				return sub
 			}
 		}
-	}()
-	return nil
+		slog.Warn("Audit worker waiting for NATS", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
 }
 
-func (w *auditWorker) Stop() { w.wg.Wait() }
+// IngestStats() reports consumer lag from JetStream along with the worker's own counters
+func (w *AuditWorker) IngestStats(ctx context.Context) (domain.IngestStats, error) {
+	stats := w.stats.snapshot()
+	stats.Workers = w.concurrency
+	stats.BatchSize = w.batchSize
+
+	sub := w.sub.Load()
+	if sub == nil {
+		// Not subscribed yet, NATS has been unreachable since startup
+		return stats, nil
+	}
+
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return stats, mapError(err)
+	}
+	stats.Connected = true
+	stats.Pending = info.NumPending
+	stats.InFlight = info.NumAckPending
+	stats.Redelivered = info.NumRedelivered
+
+	return stats, nil
+}
@@ -0,0 +1,146 @@
+// Package nats
+// This one holds the dead letter queue shared by every event subscriber
+package nats
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/nats-io/nats.go"
+)
+
+// Each consumer dead letters on its own subject, dlq.<consumer>
+const (
+	DeadLetterStream        = "EVENTS_DLQ"
+	deadLetterSubjectPrefix = "dlq."
+
+	// Dead letters are kept this long before JetStream drops them
+	deadLetterMaxAge = 30 * 24 * time.Hour
+)
+
+// Headers carried by a dead letter
+const (
+	headerDLQSubject    = "Dlq-Original-Subject"
+	headerDLQConsumer   = "Dlq-Consumer"
+	headerDLQReason     = "Dlq-Reason"
+	headerDLQDeliveries = "Dlq-Deliveries"
+	headerDLQFailedAt   = "Dlq-Failed-At"
+
+	// headerReplayFor marks a replayed event, only the named consumer handles it
+	headerReplayFor = "Replay-For"
+)
+
+// replayedForOther reports whether msg is a replay meant for another consumer
+func replayedForOther(msg *nats.Msg, consumer string) bool {
+	target := msg.Header.Get(headerReplayFor)
+	return target != "" && target != consumer
+}
+
+// deadLetter copies msg to the dead letter stream along with which consumer failed it and why
+func deadLetter(js nats.JetStreamContext, msg *nats.Msg, consumer, reason string, deliveries uint64) error {
+	dl := nats.NewMsg(deadLetterSubjectPrefix + consumer)
+	dl.Data = msg.Data
+	dl.Header.Set(headerDLQSubject, msg.Subject)
+	dl.Header.Set(headerDLQConsumer, consumer)
+	dl.Header.Set(headerDLQReason, reason)
+	dl.Header.Set(headerDLQDeliveries, strconv.FormatUint(deliveries, 10))
+	dl.Header.Set(headerDLQFailedAt, time.Now().UTC().Format(time.RFC3339Nano))
+
+	_, err := js.PublishMsg(dl)
+	return err
+}
+
+type DeadLetterQueue struct {
+	js nats.JetStreamContext
+}
+
+func NewDeadLetterQueue(js nats.JetStreamContext) *DeadLetterQueue {
+	return &DeadLetterQueue{js: js}
+}
+
+// List() reads the stream by sequence, skipping messages already replayed
+func (q *DeadLetterQueue) List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	info, err := q.js.StreamInfo(DeadLetterStream, nats.Context(ctx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	list := []*domain.DeadLetter{}
+	seq := max(info.State.FirstSeq, filter.AfterSeq+1)
+	for ; seq <= info.State.LastSeq && len(list) < filter.Limit; seq++ {
+		raw, err := q.js.GetMsg(DeadLetterStream, seq, nats.Context(ctx))
+		if errors.Is(err, nats.ErrMsgNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, mapError(err)
+		}
+		list = append(list, toDeadLetter(raw))
+	}
+
+	return list, nil
+}
+
+// Replay() publishes the payload to its original subject before deleting it,
+// a crash in between leaves a duplicate rather than a lost event.
+// The replay is addressed to the consumer that failed it, the others skip it.
+func (q *DeadLetterQueue) Replay(ctx context.Context, seq uint64) (*domain.DeadLetter, error) {
+	raw, err := q.js.GetMsg(DeadLetterStream, seq, nats.Context(ctx))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	dl := toDeadLetter(raw)
+
+	msg := nats.NewMsg(dl.Subject)
+	msg.Data = dl.Payload
+	msg.Header.Set(headerReplayFor, dl.Consumer)
+	if _, err := q.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return nil, mapError(err)
+	}
+
+	if err := q.js.DeleteMsg(DeadLetterStream, seq, nats.Context(ctx)); err != nil {
+		return nil, mapError(err)
+	}
+
+	return dl, nil
+}
+
+func toDeadLetter(raw *nats.RawStreamMsg) *domain.DeadLetter {
+	dl := &domain.DeadLetter{
+		Seq:      raw.Sequence,
+		Subject:  raw.Header.Get(headerDLQSubject),
+		Consumer: raw.Header.Get(headerDLQConsumer),
+		Reason:   raw.Header.Get(headerDLQReason),
+		FailedAt: raw.Time,
+		Payload:  raw.Data,
+	}
+	if dl.Consumer == "" {
+		dl.Consumer = strings.TrimPrefix(raw.Subject, deadLetterSubjectPrefix)
+	}
+	dl.Deliveries, _ = strconv.Atoi(raw.Header.Get(headerDLQDeliveries))
+	if t, err := time.Parse(time.RFC3339Nano, raw.Header.Get(headerDLQFailedAt)); err == nil {
+		dl.FailedAt = t
+	}
+	return dl
+}
+
+// mapError translates JetStream errors into domain errors
+func mapError(err error) error {
+	if errors.Is(err, nats.ErrMsgNotFound) {
+		return &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "the requested resource was not found",
+			Err:     err,
+		}
+	}
+
+	return &domain.AppError{
+		Code:    domain.CodeUnavailable,
+		Message: "event stream unavailable",
+		Err:     err,
+	}
+}
@@ -0,0 +1,183 @@
+// Package nats
+// This one is the domain event bus on JetStream
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/nats-io/nats.go"
+)
+
+type EventBus struct {
+	js         nats.JetStreamContext
+	maxDeliver int
+}
+
+// NewEventBus returns a bus whose subscribers dead letter an event after maxDeliver failed attempts
+func NewEventBus(js nats.JetStreamContext, maxDeliver int) *EventBus {
+	return &EventBus{js: js, maxDeliver: maxDeliver}
+}
+
+// Publish() sends the event straight to JetStream. Services publish through the outbox
+// instead, this is for events that don't need to commit with a database change.
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.js.Publish(event.Subject(), data, nats.MsgId(event.ID), nats.Context(ctx))
+	return err
+}
+
+// Subscribe() returns a durable pull consumer named name. A single pattern is filtered
+// by the server, several are filtered here from the whole stream.
+func (b *EventBus) Subscribe(name string, patterns []string, handler ports.EventHandler) ports.BackgroundWorker {
+	filter := EventsSubjects
+	if len(patterns) == 1 {
+		filter = domain.EventSubjectPrefix + patterns[0]
+	}
+
+	return &subscriber{
+		js:         b.js,
+		name:       name,
+		filter:     filter,
+		patterns:   patterns,
+		handler:    handler,
+		maxDeliver: b.maxDeliver,
+	}
+}
+
+// subscriberFetch is how many messages a subscriber pulls at a time
+const subscriberFetch = 10
+
+type subscriber struct {
+	js         nats.JetStreamContext
+	name       string
+	filter     string
+	patterns   []string
+	handler    ports.EventHandler
+	maxDeliver int
+	wg         sync.WaitGroup
+}
+
+// Start() handles events one at a time until ctx is done, a panicking handler crashes the worker
+func (s *subscriber) Start(ctx context.Context) (err error) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s subscriber panic: %v", s.name, r)
+		}
+	}()
+
+	sub := s.subscribe(ctx)
+	if sub == nil {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			msgs, err := sub.Fetch(subscriberFetch, nats.MaxWait(1*time.Second))
+			if err != nil {
+				continue
+			}
+
+			// Fetched messages are finished even if shutdown starts meanwhile
+			for _, msg := range msgs {
+				s.handle(context.WithoutCancel(ctx), msg)
+			}
+		}
+	}
+}
+
+func (s *subscriber) Stop() { s.wg.Wait() }
+
+func (s *subscriber) handle(ctx context.Context, msg *nats.Msg) {
+	if replayedForOther(msg, s.name) {
+		msg.Ack()
+		return
+	}
+
+	deliveries := uint64(1)
+	if meta, err := msg.Metadata(); err == nil {
+		deliveries = meta.NumDelivered
+	}
+
+	var event domain.Event
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		s.deadLetter(msg, "undecodable payload: "+err.Error(), deliveries)
+		return
+	}
+
+	if !s.matches(event.Type) {
+		msg.Ack()
+		return
+	}
+
+	if err := s.handler(ctx, event); err != nil {
+		if deliveries >= uint64(s.maxDeliver) {
+			s.deadLetter(msg, err.Error(), deliveries)
+			return
+		}
+
+		delay := backoff(int(deliveries))
+		slog.Warn("Event handler failed, retrying", "consumer", s.name, "event_id", event.ID, "type", event.Type, "deliveries", deliveries, "retry_in", delay, "error", err)
+		msg.NakWithDelay(delay)
+		return
+	}
+
+	msg.Ack()
+}
+
+func (s *subscriber) matches(eventType string) bool {
+	for _, p := range s.patterns {
+		if domain.MatchEventType(p, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// deadLetter parks the message in the DLQ and acks it, if the DLQ is unreachable the message is redelivered
+func (s *subscriber) deadLetter(msg *nats.Msg, reason string, deliveries uint64) {
+	if err := deadLetter(s.js, msg, s.name, reason, deliveries); err != nil {
+		slog.Error("Dead lettering event failed", "consumer", s.name, "reason", reason, "error", err)
+		msg.NakWithDelay(backoff(int(deliveries)))
+		return
+	}
+
+	slog.Error("Event dead lettered", "consumer", s.name, "subject", msg.Subject, "reason", reason, "deliveries", deliveries)
+	msg.Ack()
+}
+
+// subscribe retries until it gets a subscription, returns nil once ctx is done
+func (s *subscriber) subscribe(ctx context.Context) *nats.Subscription {
+	for {
+		err := EnsureStreams(s.js)
+		if err == nil {
+			var sub *nats.Subscription
+			sub, err = s.js.PullSubscribe(s.filter, s.name, nats.BindStream(EventsStream))
+			if err == nil {
+				return sub
+			}
+		}
+		slog.Warn("Event subscriber waiting for NATS", "consumer", s.name, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package nats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// ingestCounters tracks what the audit worker has done since startup
+type ingestCounters struct {
+	storedTotal  atomic.Uint64
+	batches      atomic.Uint64
+	fallbacks    atomic.Uint64
+	retried      atomic.Uint64
+	deadLettered atomic.Uint64
+
+	// Unix nanos of the last successful batch and how long its insert took
+	lastBatchAt atomic.Int64
+	lastBatchNs atomic.Int64
+
+	rate rateMeter
+}
+
+func (c *ingestCounters) batch(n int, took time.Duration) {
+	c.batches.Add(1)
+	c.lastBatchAt.Store(time.Now().UnixNano())
+	c.lastBatchNs.Store(int64(took))
+	c.stored(n)
+}
+
+func (c *ingestCounters) stored(n int) {
+	c.storedTotal.Add(uint64(n))
+	c.rate.add(n)
+}
+
+func (c *ingestCounters) snapshot() domain.IngestStats {
+	s := domain.IngestStats{
+		Stored:          c.storedTotal.Load(),
+		Batches:         c.batches.Load(),
+		FallbackBatches: c.fallbacks.Load(),
+		Retried:         c.retried.Load(),
+		DeadLettered:    c.deadLettered.Load(),
+		PerSecond:       c.rate.perSecond(),
+		LastBatchTook:   time.Duration(c.lastBatchNs.Load()),
+	}
+	if at := c.lastBatchAt.Load(); at > 0 {
+		t := time.Unix(0, at)
+		s.LastBatchAt = &t
+	}
+	return s
+}
+
+// rateMeter counts events in one second buckets over a sliding minute
+type rateMeter struct {
+	mu      sync.Mutex
+	buckets [60]struct {
+		sec int64
+		n   uint64
+	}
+}
+
+func (m *rateMeter) add(n int) {
+	now := time.Now().Unix()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := &m.buckets[now%60]
+	if b.sec != now {
+		b.sec = now
+		b.n = 0
+	}
+	b.n += uint64(n)
+}
+
+func (m *rateMeter) perSecond() float64 {
+	now := time.Now().Unix()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var total uint64
+	for _, b := range m.buckets {
+		if now-b.sec < 60 {
+			total += b.n
+		}
+	}
+	return float64(total) / 60
+}
@@ -3,13 +3,38 @@
 package nats
 
 import (
+	"errors"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/nats-io/nats.go"
 )
 
+// Streams and subjects the app publishes to. Every event type has its own subject
+// under events., see domain.Event.Subject, and each subscriber is a durable consumer on it.
+const (
+	EventsStream   = "EVENTS"
+	EventsSubjects = domain.EventSubjectPrefix + ">"
+)
+
+// NewNATS connects in the background when the server is not reachable yet.
+// Publishes fail until it is, which the outbox relay retries, so the app can start without NATS.
 func NewNATS(url string) (nats.JetStreamContext, error) {
-	nc, err := nats.Connect(url)
+	nc, err := nats.Connect(url,
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ConnectHandler(func(c *nats.Conn) {
+			slog.Info("NATS connected", "url", c.ConnectedUrl())
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("NATS disconnected", "error", err)
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			slog.Info("NATS reconnected", "url", c.ConnectedUrl())
+		}),
+	)
 	if err != nil {
 		slog.Info("NATS connection fialed", "url", url)
 		return nil, err
@@ -21,19 +46,89 @@ func NewNATS(url string) (nats.JetStreamContext, error) {
 		return nil, err
 	}
 
-	slog.Info("NATS connected", "url", url)
+	if nc.IsConnected() {
+		slog.Info("NATS connected", "url", url)
+	} else {
+		slog.Warn("NATS not reachable yet, retrying in the background", "url", url)
+	}
 
 	return js, err
 }
 
-func InitEventStream(js nats.JetStreamContext, streamName string, subjeccts []string) error {
+// The event stream keeps events after every subscriber handled them so live viewers can resume from recent history
+const eventStreamMaxAge = 7 * 24 * time.Hour
+
+var (
+	streamsMu    sync.Mutex
+	streamsReady bool
+)
+
+// EnsureStreams creates every stream the app uses, it is safe to call repeatedly
+func EnsureStreams(js nats.JetStreamContext) error {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	// Only the first successful call runs, so a stream is never recreated under a live subscription
+	if streamsReady {
+		return nil
+	}
+
+	err := ensureStream(js, &nats.StreamConfig{
+		Name:      EventsStream,
+		Subjects:  []string{EventsSubjects},
+		Retention: nats.LimitsPolicy,
+		Storage:   nats.FileStorage,
+		MaxAge:    eventStreamMaxAge,
+	})
+	if err != nil {
+		return err
+	}
 
-	_, err := js.AddStream(&nats.StreamConfig{
-		Name:      streamName,
-		Subjects:  subjeccts,
-		Retention: nats.WorkQueuePolicy,
+	// Dead letters stay until replayed or aged out
+	err = ensureStream(js, &nats.StreamConfig{
+		Name:      DeadLetterStream,
+		Subjects:  []string{deadLetterSubjectPrefix + ">"},
+		Retention: nats.LimitsPolicy,
 		Storage:   nats.FileStorage,
+		MaxAge:    deadLetterMaxAge,
 	})
+	if err != nil {
+		return err
+	}
+
+	streamsReady = true
+	return nil
+}
+
+// ensureStream creates the stream or brings an existing one in line with cfg.
+// Retention can't be changed in place, so an empty stream is recreated and one
+// still holding messages is left alone until a later start finds it drained.
+func ensureStream(js nats.JetStreamContext, cfg *nats.StreamConfig) error {
+	_, err := js.AddStream(cfg)
+	if err == nil || !errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		return err
+	}
+
+	info, err := js.StreamInfo(cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	if info.Config.Retention != cfg.Retention {
+		if info.State.Msgs > 0 {
+			slog.Warn("Stream retention outdated, it is recreated once empty",
+				"stream", cfg.Name, "have", info.Config.Retention.String(), "want", cfg.Retention.String(), "messages", info.State.Msgs)
+			return nil
+		}
+
+		slog.Info("Recreating stream with new retention", "stream", cfg.Name, "retention", cfg.Retention.String())
+		if err := js.DeleteStream(cfg.Name); err != nil {
+			return err
+		}
+		_, err = js.AddStream(cfg)
+		return err
+	}
 
+	_, err = js.UpdateStream(cfg)
 	return err
 }
@@ -0,0 +1,132 @@
+// Package nats
+// This one relays the transactional outbox to JetStream
+package nats
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// How long a claimed batch is hidden from other relays, a crashed relay's rows come back after this
+	relayLease = 30 * time.Second
+
+	relayBaseBackoff = time.Second
+	relayMaxBackoff  = 5 * time.Minute
+
+	// Sent rows are cleaned up once every this many polls
+	relayPurgeEvery = 600
+)
+
+type outboxRelay struct {
+	js        nats.JetStreamContext
+	repo      ports.OutboxRepository
+	interval  time.Duration
+	batchSize int
+	retention time.Duration
+	streams   bool
+	wg        sync.WaitGroup
+}
+
+// NewOutboxRelay returns a worker that forwards outbox rows to JetStream and marks them sent.
+// Failed rows are retried with exponential backoff, relayed rows are kept for retention.
+func NewOutboxRelay(js nats.JetStreamContext, repo ports.OutboxRepository, interval time.Duration, batchSize int, retention time.Duration) ports.BackgroundWorker {
+	return &outboxRelay{
+		js:        js,
+		repo:      repo,
+		interval:  interval,
+		batchSize: batchSize,
+		retention: retention,
+	}
+}
+
+func (w *outboxRelay) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	polls := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// Keep draining while full batches come back so a backlog clears quickly
+			for w.relay(ctx) == w.batchSize && ctx.Err() == nil {
+			}
+
+			polls++
+			if polls%relayPurgeEvery == 0 {
+				w.purge(ctx)
+			}
+		}
+	}
+}
+
+func (w *outboxRelay) Stop() { w.wg.Wait() }
+
+// relay publishes one claimed batch and returns its size
+func (w *outboxRelay) relay(ctx context.Context) int {
+	// Streams could not be created if NATS was down at startup, rows wait in the outbox until they are
+	if !w.streams {
+		if err := EnsureStreams(w.js); err != nil {
+			slog.Warn("Outbox relay waiting for NATS", "error", err)
+			return 0
+		}
+		w.streams = true
+	}
+
+	msgs, err := w.repo.Claim(ctx, w.batchSize, relayLease)
+	if err != nil {
+		slog.Error("Outbox claim failed", "error", err)
+		return 0
+	}
+
+	for _, m := range msgs {
+		// The outbox UUID doubles as the JetStream message ID, a row relayed twice is deduplicated
+		_, err := w.js.Publish(m.Subject, m.Payload, nats.MsgId(m.UUID), nats.Context(ctx))
+		if err != nil {
+			next := time.Now().Add(backoff(m.Attempts))
+			slog.Warn("Outbox relay failed", "outbox_id", m.UUID, "subject", m.Subject, "attempts", m.Attempts, "retry_at", next, "error", err)
+			if err := w.repo.MarkFailed(ctx, m.ID, err.Error(), next); err != nil {
+				slog.Error("Outbox mark failed failed", "outbox_id", m.UUID, "error", err)
+			}
+			continue
+		}
+
+		if err := w.repo.MarkSent(ctx, m.ID); err != nil {
+			// The lease runs out and the row is published again, the MsgId keeps it a duplicate
+			slog.Error("Outbox mark sent failed", "outbox_id", m.UUID, "error", err)
+		}
+	}
+
+	return len(msgs)
+}
+
+func (w *outboxRelay) purge(ctx context.Context) {
+	n, err := w.repo.PurgeSent(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		slog.Error("Outbox purge failed", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("Outbox purged", "rows", n)
+	}
+}
+
+// backoff doubles from relayBaseBackoff per attempt up to relayMaxBackoff
+func backoff(attempts int) time.Duration {
+	d := float64(relayBaseBackoff) * math.Pow(2, float64(max(attempts-1, 0)))
+	if d > float64(relayMaxBackoff) {
+		return relayMaxBackoff
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,83 @@
+// Package postgres
+// This one implements the Locker port with postgres advisory locks
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// AdvisoryLocker uses session level advisory locks. The lock lives on a dedicated
+// connection, so if the holding replica dies the connection drops and the lock is freed.
+// The ttl is not needed for that and is ignored.
+type AdvisoryLocker struct {
+	db *sql.DB
+}
+
+func NewAdvisoryLocker(db *sql.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+func (l *AdvisoryLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (ports.Lock, bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, MapError(err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, MapError(err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	lock := &advisoryLock{conn: conn, key: key}
+	if err := conn.QueryRowContext(ctx, `SELECT nextval('lock_fence_seq')`).Scan(&lock.token); err != nil {
+		lock.Release(context.Background())
+		return nil, false, MapError(err)
+	}
+
+	return lock, true, nil
+}
+
+type advisoryLock struct {
+	conn  *sql.Conn
+	key   string
+	token uint64
+}
+
+func (l *advisoryLock) Token() uint64 { return l.token }
+
+// Renew() checks the session still holds the lock, it lasts as long as the connection does
+func (l *advisoryLock) Renew(ctx context.Context, ttl time.Duration) error {
+	var held bool
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM pg_locks
+	              WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted
+	                AND objid = hashtext($1)::oid
+	          )`
+	if err := l.conn.QueryRowContext(ctx, query, l.key).Scan(&held); err != nil || !held {
+		return domain.ErrLockLost
+	}
+	return nil
+}
+
+func (l *advisoryLock) Release(ctx context.Context) error {
+	defer l.conn.Close()
+
+	// Unlock on a fresh context, the caller's one may already be cancelled
+	if _, err := l.conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, l.key); err != nil {
+		slog.Error("Advisory unlock failed", "key", l.key, "error", err)
+		return MapError(err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExpiredPartitions() asks partman for the audit_log children and their ranges.
+// The default partition is left out, it has no range to expire.
+func (r *AuditRepo) ExpiredPartitions(ctx context.Context, before time.Time) ([]domain.AuditPartition, error) {
+	query := `SELECT p.partition_schemaname, p.partition_tablename, i.child_start_time, i.child_end_time
+	          FROM partman.show_partitions('public.audit_log') p
+	          CROSS JOIN LATERAL partman.show_partition_info(p.partition_schemaname || '.' || p.partition_tablename) i
+	          WHERE i.child_end_time <= $1
+	          ORDER BY i.child_start_time`
+
+	rows, err := r.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	defer rows.Close()
+
+	var list []domain.AuditPartition
+	for rows.Next() {
+		var p domain.AuditPartition
+		if err := rows.Scan(&p.Schema, &p.Table, &p.From, &p.To); err != nil {
+			return nil, MapError(err)
+		}
+		list = append(list, p)
+	}
+	return list, MapError(rows.Err())
+}
+
+// StreamPartition() reads the child table directly, rows from before the hash chain come first
+func (r *AuditRepo) StreamPartition(ctx context.Context, p domain.AuditPartition, fn func(*domain.Audit) error) error {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash
+	          FROM ` + pgx.Identifier{p.Schema, p.Table}.Sanitize() + `
+	          ORDER BY chain_seq NULLS FIRST, id`
+
+	rows, err := r.db.QueryxContext(ctx, query)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row auditRow
+		if err := rows.StructScan(&row); err != nil {
+			return MapError(err)
+		}
+		a, err := row.toDomain()
+		if err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+
+	return MapError(rows.Err())
+}
+
+// DropPartition() detaches the child first so queries on audit_log never see it half dropped
+func (r *AuditRepo) DropPartition(ctx context.Context, p domain.AuditPartition) error {
+	table := pgx.Identifier{p.Schema, p.Table}.Sanitize()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return MapError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE public.audit_log DETACH PARTITION `+table); err != nil {
+		return MapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE `+table); err != nil {
+		return MapError(err)
+	}
+
+	return MapError(tx.Commit())
+}
+
+// importArchivedQuery spells the actor_id cast out with CAST, sqlx reads the : of a :: cast as a named parameter
+const importArchivedQuery = `INSERT INTO audit_archive_import
+	(id, uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash, archive)
+VALUES
+	(:id, :uuid, :event_type, CAST(NULLIF(:actor_id, '') AS uuid), :payload, :created_at,
+	 NULLIF(:chain_seq, 0), NULLIF(:prev_hash, ''), NULLIF(:hash, ''), :archive)
+ON CONFLICT (uuid) DO NOTHING`
+
+// ImportArchived() skips rows already imported, so an archive can be loaded twice safely
+func (r *AuditRepo) ImportArchived(ctx context.Context, archive string, logs []domain.Audit) (int64, error) {
+	if len(logs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, MapError(err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareNamedContext(ctx, importArchivedQuery)
+	if err != nil {
+		return 0, MapError(err)
+	}
+	defer stmt.Close()
+
+	var imported int64
+	for _, a := range logs {
+		res, err := stmt.ExecContext(ctx, map[string]any{
+			"id":         a.ID,
+			"uuid":       a.UUID,
+			"event_type": a.EventType,
+			"actor_id":   a.ActorID,
+			"payload":    a.Payload,
+			"created_at": a.CreatedAt,
+			"chain_seq":  a.ChainSeq,
+			"prev_hash":  a.PrevHash,
+			"hash":       a.Hash,
+			"archive":    archive,
+		})
+		if err != nil {
+			return 0, MapError(err)
+		}
+		n, _ := res.RowsAffected()
+		imported += n
+	}
+
+	return imported, MapError(tx.Commit())
+}
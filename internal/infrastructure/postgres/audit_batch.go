@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+var auditCopyColumns = []string{"uuid", "event_type", "actor_id", "payload", "created_at", "chain_seq", "prev_hash", "hash"}
+
+// CreateBatch() chains the entries in order and writes them with COPY in one transaction.
+// Either every entry is stored or none is, so callers can ack the whole batch after it returns.
+// Entries whose uuid is already stored, or repeated within the batch, are skipped.
+func (r *AuditRepo) CreateBatch(ctx context.Context, logs []domain.Audit) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return MapError(err)
+	}
+	defer conn.Close()
+
+	// COPY is only reachable on the native pgx connection underneath database/sql
+	err = conn.Raw(func(driverConn any) error {
+		sc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		return pgx.BeginFunc(ctx, sc.Conn(), func(tx pgx.Tx) error {
+			return copyChained(ctx, tx, logs)
+		})
+	})
+	return MapError(err)
+}
+
+// copyChained locks the chain head, seals the entries against it and copies them in.
+// q has to be in a transaction, the head stays locked until it ends.
+func copyChained(ctx context.Context, q pgxQuerier, logs []domain.Audit) error {
+	head, err := lockChainHead(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	// Claimed under the head lock, before sealing, a skipped entry must not take a chain_seq
+	if logs, err = claimEventIDs(ctx, q, logs); err != nil || len(logs) == 0 {
+		return err
+	}
+
+	rows := make([][]any, len(logs))
+	for i, l := range logs {
+		a, err := sealChained(&head, l)
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(a.Payload)
+		if err != nil {
+			return err
+		}
+
+		// COPY uses the binary protocol, UUIDs have to be parsed rather than sent as text
+		var id, actor pgtype.UUID
+		if err := id.Scan(a.UUID); err != nil {
+			return fmt.Errorf("audit %q: %w", a.UUID, err)
+		}
+		// System events (e.g. scheduled jobs) have no actor, store those as NULL
+		if a.ActorID != "" {
+			if err := actor.Scan(a.ActorID); err != nil {
+				return fmt.Errorf("audit %q actor: %w", a.UUID, err)
+			}
+		}
+
+		rows[i] = []any{id, a.EventType, actor, payload, a.CreatedAt, a.ChainSeq, a.PrevHash, a.Hash}
+	}
+
+	if _, err := q.CopyFrom(ctx, pgx.Identifier{"audit_log"}, auditCopyColumns, pgx.CopyFromRows(rows)); err != nil {
+		return err
+	}
+
+	return advanceChainHead(ctx, q, head)
+}
+
+// lockChainHead reads the chain head FOR UPDATE, writers queue up behind it
+func lockChainHead(ctx context.Context, q pgxQuerier) (chainHeadRow, error) {
+	var head chainHeadRow
+	err := q.QueryRow(ctx, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1 FOR UPDATE`).
+		Scan(&head.LastSeq, &head.LastHash, &head.LastCreatedAt)
+	return head, err
+}
+
+func advanceChainHead(ctx context.Context, q pgxQuerier, head chainHeadRow) error {
+	_, err := q.Exec(ctx,
+		`UPDATE audit_chain_head SET last_seq = $1, last_hash = $2, last_created_at = $3 WHERE id = 1`,
+		head.LastSeq, head.LastHash, head.LastCreatedAt)
+	return err
+}
+
+// claimEventIDQuery records one event id, it affects no row when the id is already stored
+const claimEventIDQuery = `INSERT INTO audit_event_id (uuid) VALUES ($1) ON CONFLICT DO NOTHING`
+
+// claimEventIDs records the ids of logs in audit_event_id and returns, in order, the entries
+// whose id was not stored yet. q has to be in the transaction that stores them.
+func claimEventIDs(ctx context.Context, q pgxQuerier, logs []domain.Audit) ([]domain.Audit, error) {
+	ids := make([]string, len(logs))
+	for i, l := range logs {
+		ids[i] = l.UUID
+	}
+
+	rows, err := q.Query(ctx,
+		`INSERT INTO audit_event_id (uuid) SELECT unnest($1::text[])::uuid ON CONFLICT DO NOTHING RETURNING uuid::text`, ids)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make(map[string]bool, len(claimed))
+	for _, id := range claimed {
+		fresh[id] = true
+	}
+
+	// Postgres hands the uuids back in canonical form, the first copy of a repeated id wins
+	kept := logs[:0:0]
+	for _, l := range logs {
+		if id := strings.ToLower(l.UUID); fresh[id] {
+			kept = append(kept, l)
+			delete(fresh, id)
+		}
+	}
+	return kept, nil
+}
@@ -5,6 +5,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/jmoiron/sqlx"
@@ -20,12 +24,302 @@ func NewAuditRepo(db *sql.DB) *AuditRepo {
 	}
 }
 
+// auditRow is the raw shape of an audit_log row, JSONB and nullable UUIDs need decoding
+type auditRow struct {
+	ID        int64     `db:"id"`
+	UUID      string    `db:"uuid"`
+	EventType string    `db:"event_type"`
+	ActorID   *string   `db:"actor_id"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+	ChainSeq  *int64    `db:"chain_seq"`
+	PrevHash  *string   `db:"prev_hash"`
+	Hash      *string   `db:"hash"`
+}
+
+func (r auditRow) toDomain() (*domain.Audit, error) {
+	a := &domain.Audit{
+		ID:        r.ID,
+		UUID:      r.UUID,
+		EventType: r.EventType,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.ActorID != nil {
+		a.ActorID = *r.ActorID
+	}
+	// Rows written before the hash chain existed have no chain columns
+	if r.ChainSeq != nil {
+		a.ChainSeq = *r.ChainSeq
+	}
+	if r.PrevHash != nil {
+		a.PrevHash = *r.PrevHash
+	}
+	if r.Hash != nil {
+		a.Hash = *r.Hash
+	}
+	if len(r.Payload) > 0 {
+		if err := json.Unmarshal(r.Payload, &a.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Create() links the entry to the chain tip and inserts it in one transaction, the caller's if there is one.
+// The head row lock serializes writers so every row gets the next chain_seq.
+// An entry whose uuid is already stored is skipped, redelivered events are a no-op.
 func (r *AuditRepo) Create(ctx context.Context, auditLog domain.Audit) error {
-	query := `INSERT INTO audit_log 
-						(uuid, event_type, actor_id, payload) VALUES 
-						(:uuid, :event_type, :actor_id, :payload)`
+	return inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var head chainHeadRow
+		if err := tx.GetContext(ctx, &head, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1 FOR UPDATE`); err != nil {
+			return MapError(err)
+		}
+
+		res, err := tx.ExecContext(ctx, claimEventIDQuery, auditLog.UUID)
+		if err != nil {
+			return MapError(err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return MapError(err)
+		}
+
+		if err := r.insertChained(ctx, tx, &head, auditLog); err != nil {
+			return err
+		}
+
+		return updateChainHead(ctx, tx, head)
+	})
+}
+
+// chainHeadRow mirrors audit_chain_head
+type chainHeadRow struct {
+	LastSeq       int64      `db:"last_seq"`
+	LastHash      string     `db:"last_hash"`
+	LastCreatedAt *time.Time `db:"last_created_at"`
+}
+
+// sealChained links one entry to the head and advances the head in memory
+func sealChained(head *chainHeadRow, a domain.Audit) (domain.Audit, error) {
+	// created_at is taken under the lock so it never goes backwards along the chain
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if head.LastCreatedAt != nil && now.Before(*head.LastCreatedAt) {
+		now = *head.LastCreatedAt
+	}
+
+	a.CreatedAt = now
+	a.ChainSeq = head.LastSeq + 1
+	a.PrevHash = head.LastHash
+
+	hash, err := domain.ChainHash(a.PrevHash, a)
+	if err != nil {
+		return a, err
+	}
+	a.Hash = hash
+
+	head.LastSeq = a.ChainSeq
+	head.LastHash = a.Hash
+	head.LastCreatedAt = &now
+	return a, nil
+}
+
+// System events (e.g. scheduled jobs) have no actor, store those as NULL.
+// The cast is spelled out with CAST, sqlx reads the : of a :: cast as a named parameter.
+const insertChainedQuery = `INSERT INTO audit_log
+	(uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash) VALUES
+	(:uuid, :event_type, CAST(NULLIF(:actor_id, '') AS uuid), :payload, :created_at, :chain_seq, :prev_hash, :hash)`
+
+// insertChained seals one entry against the head and inserts it
+func (r *AuditRepo) insertChained(ctx context.Context, tx *sqlx.Tx, head *chainHeadRow, a domain.Audit) error {
+	a, err := sealChained(head, a)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.NamedExecContext(ctx, insertChainedQuery, a); err != nil {
+		return MapError(err)
+	}
+	return nil
+}
+
+func updateChainHead(ctx context.Context, tx *sqlx.Tx, head chainHeadRow) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE audit_chain_head SET last_seq = $1, last_hash = $2, last_created_at = $3 WHERE id = 1`,
+		head.LastSeq, head.LastHash, head.LastCreatedAt)
+	return MapError(err)
+}
+
+// Head() reads the tip of the chain without locking it
+func (r *AuditRepo) Head(ctx context.Context) (domain.ChainHead, error) {
+	var head chainHeadRow
+	if err := conn(ctx, r.db).GetContext(ctx, &head, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1`); err != nil {
+		return domain.ChainHead{}, MapError(err)
+	}
+
+	h := domain.ChainHead{Seq: head.LastSeq, Hash: head.LastHash}
+	if head.LastCreatedAt != nil {
+		h.CreatedAt = *head.LastCreatedAt
+	}
+	return h, nil
+}
+
+// Walk() streams chained rows in [from, to) ordered by chain_seq.
+// The created_at bounds keep the scan inside the matching partitions.
+func (r *AuditRepo) Walk(ctx context.Context, from, to time.Time, fn func(*domain.Audit) error) error {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash
+              FROM audit_log
+              WHERE created_at >= $1 AND created_at < $2 AND chain_seq IS NOT NULL
+              ORDER BY chain_seq`
+
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, from, to)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row auditRow
+		if err := rows.StructScan(&row); err != nil {
+			return MapError(err)
+		}
+		a, err := row.toDomain()
+		if err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+
+	return MapError(rows.Err())
+}
+
+// CreateCheckpoint() stores a signed checkpoint
+func (r *AuditRepo) CreateCheckpoint(ctx context.Context, cp *domain.Checkpoint) error {
+	query := `INSERT INTO audit_checkpoint (chain_seq, chain_hash, chain_created_at, signature)
+	          VALUES (:chain_seq, :chain_hash, :chain_created_at, :signature)
+	          RETURNING id, created_at`
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, cp)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.StructScan(cp)
+	}
+	return MapError(rows.Err())
+}
+
+// LatestCheckpoint() reads the newest checkpoint
+func (r *AuditRepo) LatestCheckpoint(ctx context.Context) (*domain.Checkpoint, error) {
+	cp := &domain.Checkpoint{}
+	if err := conn(ctx, r.db).GetContext(ctx, cp, `SELECT * FROM audit_checkpoint ORDER BY chain_seq DESC LIMIT 1`); err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+// CheckpointBefore() reads the newest checkpoint of a chain tip created at or before at
+func (r *AuditRepo) CheckpointBefore(ctx context.Context, at time.Time) (*domain.Checkpoint, error) {
+	cp := &domain.Checkpoint{}
+	query := `SELECT * FROM audit_checkpoint WHERE chain_created_at <= $1 ORDER BY chain_seq DESC LIMIT 1`
+	if err := conn(ctx, r.db).GetContext(ctx, cp, query, at); err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+// Checkpoints() lists checkpoints taken over [from, to)
+func (r *AuditRepo) Checkpoints(ctx context.Context, from, to time.Time) ([]*domain.Checkpoint, error) {
+	var list []*domain.Checkpoint
+	query := `SELECT * FROM audit_checkpoint WHERE chain_created_at >= $1 AND chain_created_at < $2 ORDER BY chain_seq`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &list, query, from, to); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// Query() reads a page of audit entries newest first using keyset pagination.
+// The created_at bounds are always applied so postgres prunes partitions outside the range.
+func (r *AuditRepo) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.Audit, error) {
+	query, args, err := auditQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	defer rows.Close()
+
+	var list []*domain.Audit
+	for rows.Next() {
+		var row auditRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, MapError(err)
+		}
+		a, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, MapError(err)
+	}
+
+	return list, nil
+}
+
+// auditQuery builds the named query behind Query. Casts use CAST, sqlx reads the : of a :: cast
+// as a named parameter.
+func auditQuery(filter domain.AuditFilter) (string, map[string]any, error) {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at
+              FROM audit_log
+              WHERE created_at >= :from AND created_at < :to`
+
+	args := map[string]any{
+		"from":  filter.From,
+		"to":    filter.To,
+		"limit": filter.Limit,
+	}
+
+	if filter.ActorID != "" {
+		query += ` AND actor_id = CAST(:actor_id AS uuid)`
+		args["actor_id"] = filter.ActorID
+	}
+
+	if filter.EventType != "" {
+		query += ` AND event_type = :event_type`
+		args["event_type"] = filter.EventType
+	}
+
+	if filter.PayloadKey != "" {
+		// Containment lets the GIN index on payload do the work, one test per matching value
+		var tests []string
+		for i, v := range domain.NewPayloadMatch(filter.PayloadValue).Values() {
+			match, err := json.Marshal(map[string]any{filter.PayloadKey: v})
+			if err != nil {
+				return "", nil, err
+			}
+			name := fmt.Sprintf("payload_match_%d", i)
+			tests = append(tests, `payload @> CAST(:`+name+` AS jsonb)`)
+			args[name] = string(match)
+		}
+		query += ` AND (` + strings.Join(tests, ` OR `) + `)`
+	}
+
+	if filter.After != nil {
+		query += ` AND (created_at, id) < (:after_created_at, :after_id)`
+		args["after_created_at"] = filter.After.CreatedAt
+		args["after_id"] = filter.After.ID
+	}
 
-	_, err := r.db.NamedExecContext(ctx, query, auditLog)
+	query += ` ORDER BY created_at DESC, id DESC LIMIT :limit`
 
-	return err
+	return query, args, nil
 }
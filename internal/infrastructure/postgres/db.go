@@ -19,22 +19,36 @@ type Config struct {
 	Password string
 	DBName   string
 	PoolSize int
+	// ReplicaDSNs are read replicas of the database above, each gets a pool of PoolSize
+	ReplicaDSNs []string
+
+	// Only used by ConnectPool, PoolSize is its max
+	MinConns          int
+	HealthCheckPeriod time.Duration
+	// StatementCacheMode is pgx's query exec mode: cache_statement, cache_describe,
+	// describe_exec, exec or simple_protocol. The last two suit pgbouncer in transaction mode.
+	StatementCacheMode string
+
+	// DSN replaces Host through DBName when set
+	DSN string
 }
 
-func ConnectDB(cfg Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+func (cfg Config) dsn() string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+}
 
-	db, err := sql.Open("pgx", dsn)
+func ConnectDB(cfg Config) (*sql.DB, error) {
+	dsn := cfg.dsn()
+
+	db, err := openPool(dsn, cfg.PoolSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
+		return nil, err
 	}
 
-	// Configure the connection pool
-	db.SetMaxOpenConns(cfg.PoolSize)     // Use config setting for Max connections
-	db.SetMaxIdleConns(cfg.PoolSize / 2) // Set idle to half of max
-	db.SetConnMaxLifetime(5 * time.Minute)
-
 	// Health Check and Retry Loop
 	const maxRetries = 5
 	for i := 0; i < maxRetries; i++ {
@@ -52,3 +66,33 @@ func ConnectDB(cfg Config) (*sql.DB, error) {
 
 	return nil, fmt.Errorf("failed to connect to PostgreSQL after %d retries", maxRetries)
 }
+
+// ConnectReplicas opens a pool per replica DSN. Nothing is pinged here, a replica that is down
+// at startup stays out of rotation until the ReadRouter health check reaches it.
+func ConnectReplicas(cfg Config) ([]*sql.DB, error) {
+	replicas := make([]*sql.DB, 0, len(cfg.ReplicaDSNs))
+	for i, dsn := range cfg.ReplicaDSNs {
+		db, err := openPool(dsn, cfg.PoolSize)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas, nil
+}
+
+func openPool(dsn string, poolSize int) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Configure the connection pool
+	db.SetMaxOpenConns(poolSize)     // Use config setting for Max connections
+	db.SetMaxIdleConns(poolSize / 2) // Set idle to half of max
+	db.SetConnMaxLifetime(5 * time.Minute)
+	return db, nil
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// compileNamed binds a named query the way sqlx does before it reaches postgres.
+// A : left behind is a cast sqlx misread, postgres rejects the statement.
+func compileNamed(t *testing.T, query string, arg any) (string, []any) {
+	t.Helper()
+	bound, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		t.Fatalf("sqlx rejects the query: %v\n%s", err, query)
+	}
+	if strings.Contains(bound, ":") {
+		t.Fatalf("stray : after binding:\n%s", bound)
+	}
+	return sqlx.Rebind(sqlx.DOLLAR, bound), args
+}
+
+func TestInsertChainedQueryBinds(t *testing.T) {
+	query, args := compileNamed(t, insertChainedQuery, domain.Audit{UUID: "u", EventType: "user.created"})
+	if len(args) != 8 {
+		t.Fatalf("%d args, want 8", len(args))
+	}
+	if !strings.Contains(query, "CAST(NULLIF($3, '') AS uuid)") {
+		t.Fatalf("actor_id is not cast:\n%s", query)
+	}
+}
+
+func TestAuditQueryBindsEveryFilter(t *testing.T) {
+	now := time.Now()
+	query, args, err := auditQuery(domain.AuditFilter{
+		ActorID:      "0192f0c4-0000-7000-8000-000000000000",
+		EventType:    "user.created",
+		From:         now.Add(-time.Hour),
+		To:           now,
+		PayloadKey:   "attempts",
+		PayloadValue: "2",
+		After:        &domain.AuditCursor{CreatedAt: now, ID: 7},
+		Limit:        50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bound, values := compileNamed(t, query, args)
+	for _, want := range []string{"actor_id = CAST($3 AS uuid)", "payload @> CAST($5 AS jsonb) OR payload @> CAST($6 AS jsonb)"} {
+		if !strings.Contains(bound, want) {
+			t.Fatalf("missing %q in\n%s", want, bound)
+		}
+	}
+	if len(values) != 9 {
+		t.Fatalf("%d args, want 9", len(values))
+	}
+}
+
+func TestImportArchivedQueryBinds(t *testing.T) {
+	query, args := compileNamed(t, importArchivedQuery, map[string]any{
+		"id": int64(1), "uuid": "u", "event_type": "user.created", "actor_id": "", "payload": nil,
+		"created_at": time.Now(), "chain_seq": int64(0), "prev_hash": "", "hash": "", "archive": "public.audit_log_p2025_01",
+	})
+	if len(args) != 10 {
+		t.Fatalf("%d args, want 10", len(args))
+	}
+	if !strings.Contains(query, "CAST(NULLIF($4, '') AS uuid)") {
+		t.Fatalf("actor_id is not cast:\n%s", query)
+	}
+}
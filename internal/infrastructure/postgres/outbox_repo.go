@@ -0,0 +1,102 @@
+// Package postgres
+// This one holds the transactional outbox repository
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepo struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepo {
+	return &OutboxRepo{
+		db: sqlx.NewDb(db, "pgx"),
+	}
+}
+
+// Enqueue() stores a message for the relay
+func (r *OutboxRepo) Enqueue(ctx context.Context, msg *domain.OutboxMessage) error {
+	query := `INSERT INTO "outbox" (uuid, subject, payload)
+	          VALUES (:uuid, :subject, :payload)
+	          RETURNING id, attempts, next_attempt_at, created_at`
+
+	// JSONB wants text, a raw []byte would be sent as bytea
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, map[string]any{
+		"uuid":    msg.UUID,
+		"subject": msg.Subject,
+		"payload": string(msg.Payload),
+	})
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.StructScan(msg)
+	}
+	return MapError(rows.Err())
+}
+
+// Publish() makes the outbox an EventPublisher, the event is relayed to NATS later.
+// The event id doubles as the message id so the broker drops a relayed duplicate.
+func (r *OutboxRepo) Publish(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.Enqueue(ctx, &domain.OutboxMessage{
+		UUID:    event.ID,
+		Subject: event.Subject(),
+		Payload: data,
+	})
+}
+
+// Claim() leases due messages by pushing their next attempt past the lease.
+// SKIP LOCKED lets several relays claim disjoint batches at the same time.
+func (r *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	var list []*domain.OutboxMessage
+	query := `UPDATE "outbox" SET next_attempt_at = NOW() + $2::interval, attempts = attempts + 1
+	          WHERE id IN (
+	              SELECT id FROM "outbox"
+	              WHERE sent_at IS NULL AND next_attempt_at <= NOW()
+	              ORDER BY id
+	              LIMIT $1
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING *`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &list, query, limit, lease.String()); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// MarkSent() records a successful relay
+func (r *OutboxRepo) MarkSent(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE "outbox" SET sent_at = NOW(), last_error = NULL WHERE id = $1`, id)
+	return MapError(err)
+}
+
+// MarkFailed() records the error and schedules the next attempt
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id int64, reason string, next time.Time) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE "outbox" SET last_error = $2, next_attempt_at = $3 WHERE id = $1`, id, reason, next)
+	return MapError(err)
+}
+
+// PurgeSent() deletes relayed messages older than before
+func (r *OutboxRepo) PurgeSent(ctx context.Context, before time.Time) (int64, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM "outbox" WHERE sent_at IS NOT NULL AND sent_at < $1`, before)
+	if err != nil {
+		return 0, MapError(err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,50 @@
+// Package postgres
+// This one lets the pgx repositories run inside TxManager transactions
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// pgxQuerier is the part of the pool, a connection and a transaction the Pgx repositories use
+type pgxQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// pgxConn runs fn on the connection of the TxManager transaction in ctx, or on pool without one.
+// database/sql owns that connection, fn borrows it and must be done with it when it returns.
+func pgxConn(ctx context.Context, pool *pgxpool.Pool, fn func(q pgxQuerier) error) error {
+	st, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return fn(pool)
+	}
+
+	return st.conn.Raw(func(driverConn any) error {
+		sc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		return fn(sc.Conn())
+	})
+}
+
+// pgxInTx is inTx for the Pgx repositories, fn joins the transaction in ctx or gets one of its own
+func pgxInTx(ctx context.Context, pool *pgxpool.Pool, fn func(q pgxQuerier) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return pgxConn(ctx, pool, fn)
+	}
+
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		return fn(tx)
+	})
+}
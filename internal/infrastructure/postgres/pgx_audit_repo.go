@@ -0,0 +1,294 @@
+// Package postgres
+// This one holds the audit repository on the native pgx pool
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgxAuditRepo is the audit log and its hash chain on pgxpool. Payloads go in and out as
+// JSONB and UUIDs in their binary form. Archiving stays on AuditRepo.
+type PgxAuditRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxAuditRepo(pool *pgxpool.Pool) *PgxAuditRepo {
+	return &PgxAuditRepo{pool: pool}
+}
+
+// pgxAuditRow is an audit_log row as pgx decodes it, the chain columns are NULL on rows older than the chain
+type pgxAuditRow struct {
+	ID        int64          `db:"id"`
+	UUID      string         `db:"uuid"`
+	EventType string         `db:"event_type"`
+	ActorID   *string        `db:"actor_id"`
+	Payload   map[string]any `db:"payload"`
+	CreatedAt time.Time      `db:"created_at"`
+	ChainSeq  *int64         `db:"chain_seq"`
+	PrevHash  *string        `db:"prev_hash"`
+	Hash      *string        `db:"hash"`
+}
+
+func (r *pgxAuditRow) toDomain() *domain.Audit {
+	a := &domain.Audit{
+		ID:        r.ID,
+		UUID:      r.UUID,
+		EventType: r.EventType,
+		Payload:   r.Payload,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.ActorID != nil {
+		a.ActorID = *r.ActorID
+	}
+	if r.ChainSeq != nil {
+		a.ChainSeq = *r.ChainSeq
+	}
+	if r.PrevHash != nil {
+		a.PrevHash = *r.PrevHash
+	}
+	if r.Hash != nil {
+		a.Hash = *r.Hash
+	}
+	return a
+}
+
+// pgUUID parses a uuid for the binary protocol, an empty one is NULL
+func pgUUID(s string) (pgtype.UUID, error) {
+	var u pgtype.UUID
+	if s == "" {
+		return u, nil
+	}
+	err := u.Scan(s)
+	return u, err
+}
+
+// Create() links the entry to the chain tip and inserts it, in the caller's transaction if there is one.
+// An entry whose uuid is already stored is skipped.
+func (r *PgxAuditRepo) Create(ctx context.Context, auditLog domain.Audit) error {
+	err := pgxInTx(ctx, r.pool, func(q pgxQuerier) error {
+		head, err := lockChainHead(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		tag, err := q.Exec(ctx, claimEventIDQuery, auditLog.UUID)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+
+		a, err := sealChained(&head, auditLog)
+		if err != nil {
+			return err
+		}
+		id, err := pgUUID(a.UUID)
+		if err != nil {
+			return fmt.Errorf("audit %q: %w", a.UUID, err)
+		}
+		// System events (e.g. scheduled jobs) have no actor, the empty uuid goes in as NULL
+		actor, err := pgUUID(a.ActorID)
+		if err != nil {
+			return fmt.Errorf("audit %q actor: %w", a.UUID, err)
+		}
+
+		_, err = q.Exec(ctx, `INSERT INTO audit_log
+			(uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			id, a.EventType, actor, a.Payload, a.CreatedAt, a.ChainSeq, a.PrevHash, a.Hash)
+		if err != nil {
+			return err
+		}
+
+		return advanceChainHead(ctx, q, head)
+	})
+	return MapError(err)
+}
+
+// CreateBatch() chains the entries and copies them in one transaction, all or nothing.
+// Entries already stored are skipped.
+func (r *PgxAuditRepo) CreateBatch(ctx context.Context, logs []domain.Audit) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return MapError(pgxInTx(ctx, r.pool, func(q pgxQuerier) error {
+		return copyChained(ctx, q, logs)
+	}))
+}
+
+// Query() reads a page of audit entries newest first, see AuditRepo.Query
+func (r *PgxAuditRepo) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.Audit, error) {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at
+              FROM audit_log
+              WHERE created_at >= @from AND created_at < @to`
+
+	args := pgx.NamedArgs{
+		"from":  filter.From,
+		"to":    filter.To,
+		"limit": filter.Limit,
+	}
+
+	if filter.ActorID != "" {
+		query += ` AND actor_id = @actor_id`
+		args["actor_id"] = filter.ActorID
+	}
+
+	if filter.EventType != "" {
+		query += ` AND event_type = @event_type`
+		args["event_type"] = filter.EventType
+	}
+
+	if filter.PayloadKey != "" {
+		// Containment lets the GIN index on payload do the work, one test per matching value
+		var tests []string
+		for i, v := range domain.NewPayloadMatch(filter.PayloadValue).Values() {
+			name := fmt.Sprintf("payload_match_%d", i)
+			tests = append(tests, `payload @> @`+name)
+			args[name] = map[string]any{filter.PayloadKey: v}
+		}
+		query += ` AND (` + strings.Join(tests, ` OR `) + `)`
+	}
+
+	if filter.After != nil {
+		query += ` AND (created_at, id) < (@after_created_at, @after_id)`
+		args["after_created_at"] = filter.After.CreatedAt
+		args["after_id"] = filter.After.ID
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC LIMIT @limit`
+
+	var list []*domain.Audit
+	err := r.each(ctx, query, []any{args}, func(a *domain.Audit) error {
+		list = append(list, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Head() reads the tip of the chain without locking it
+func (r *PgxAuditRepo) Head(ctx context.Context) (domain.ChainHead, error) {
+	var head chainHeadRow
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		return q.QueryRow(ctx, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1`).
+			Scan(&head.LastSeq, &head.LastHash, &head.LastCreatedAt)
+	})
+	if err != nil {
+		return domain.ChainHead{}, MapError(err)
+	}
+
+	h := domain.ChainHead{Seq: head.LastSeq, Hash: head.LastHash}
+	if head.LastCreatedAt != nil {
+		h.CreatedAt = *head.LastCreatedAt
+	}
+	return h, nil
+}
+
+// Walk() streams chained rows in [from, to) ordered by chain_seq
+func (r *PgxAuditRepo) Walk(ctx context.Context, from, to time.Time, fn func(*domain.Audit) error) error {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash
+              FROM audit_log
+              WHERE created_at >= $1 AND created_at < $2 AND chain_seq IS NOT NULL
+              ORDER BY chain_seq`
+
+	return r.each(ctx, query, []any{from, to}, fn)
+}
+
+// CreateCheckpoint() stores a signed checkpoint
+func (r *PgxAuditRepo) CreateCheckpoint(ctx context.Context, cp *domain.Checkpoint) error {
+	query := `INSERT INTO audit_checkpoint (chain_seq, chain_hash, chain_created_at, signature)
+	          VALUES ($1, $2, $3, $4)
+	          RETURNING id, created_at`
+
+	return MapError(pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		return q.QueryRow(ctx, query, cp.Seq, cp.Hash, cp.ChainAt, cp.Signature).Scan(&cp.ID, &cp.CreatedAt)
+	}))
+}
+
+// LatestCheckpoint() reads the newest checkpoint
+func (r *PgxAuditRepo) LatestCheckpoint(ctx context.Context) (*domain.Checkpoint, error) {
+	var cp *domain.Checkpoint
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, `SELECT * FROM audit_checkpoint ORDER BY chain_seq DESC LIMIT 1`)
+		if err != nil {
+			return err
+		}
+		cp, err = pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Checkpoint])
+		return err
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+// CheckpointBefore() reads the newest checkpoint of a chain tip created at or before at
+func (r *PgxAuditRepo) CheckpointBefore(ctx context.Context, at time.Time) (*domain.Checkpoint, error) {
+	var cp *domain.Checkpoint
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, `SELECT * FROM audit_checkpoint WHERE chain_created_at <= $1 ORDER BY chain_seq DESC LIMIT 1`, at)
+		if err != nil {
+			return err
+		}
+		cp, err = pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[domain.Checkpoint])
+		return err
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+// Checkpoints() lists checkpoints taken over [from, to)
+func (r *PgxAuditRepo) Checkpoints(ctx context.Context, from, to time.Time) ([]*domain.Checkpoint, error) {
+	query := `SELECT * FROM audit_checkpoint WHERE chain_created_at >= $1 AND chain_created_at < $2 ORDER BY chain_seq`
+
+	var list []*domain.Checkpoint
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, query, from, to)
+		if err != nil {
+			return err
+		}
+		list, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[domain.Checkpoint])
+		return err
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// each hands every audit row of the query to fn, fn's own errors go back untouched
+func (r *PgxAuditRepo) each(ctx context.Context, query string, args []any, fn func(*domain.Audit) error) error {
+	var fnErr error
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			row, err := pgx.RowToAddrOfStructByNameLax[pgxAuditRow](rows)
+			if err != nil {
+				return err
+			}
+			if fnErr = fn(row.toDomain()); fnErr != nil {
+				return fnErr
+			}
+		}
+		return rows.Err()
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return MapError(err)
+}
@@ -0,0 +1,218 @@
+// Package postgres
+// This one holds the user repository on the native pgx pool
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgxUserRepo is UserRepo on pgxpool, it joins TxManager transactions the same way
+type PgxUserRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxUserRepo(pool *pgxpool.Pool) *PgxUserRepo {
+	return &PgxUserRepo{pool: pool}
+}
+
+// Create() creates a user entity
+func (r *PgxUserRepo) Create(ctx context.Context, u *domain.User) error {
+	query := `
+		INSERT INTO "user" (uuid, user_name, email, user_role, phone, password)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_status, created_at, updated_at
+	`
+
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		return q.QueryRow(ctx, query, u.UUID, u.UserName, u.Email, u.UserRole, u.Phone, u.Password).
+			Scan(&u.ID, &u.UserStatus, &u.CreatedAt, &u.UpdatedAt)
+	})
+	return MapError(err)
+}
+
+func (r *PgxUserRepo) ReadOne(ctx context.Context, id string) (*domain.User, error) {
+	return r.one(ctx, `SELECT * FROM "user" WHERE uuid = $1 AND deleted_at IS NULL`, id)
+}
+
+func (r *PgxUserRepo) ReadByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.one(ctx, `SELECT * FROM "user" WHERE email = $1 AND deleted_at IS NULL`, email)
+}
+
+func (r *PgxUserRepo) ReadOneDeleted(ctx context.Context, id string) (*domain.User, error) {
+	return r.one(ctx, `SELECT * FROM "user" WHERE uuid = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+// ReadAll() reads all the user entities with deleted users (optional)
+func (r *PgxUserRepo) ReadAll(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `SELECT id, uuid, user_name, email, phone, user_status, created_at, updated_at
+              FROM "user" WHERE 1=1`
+
+	query, args := applyUserFilter(query, filter, "@")
+	return r.list(ctx, query, pgx.NamedArgs(args))
+}
+
+// Stream() hands the filtered users to fn as they come off the wire
+func (r *PgxUserRepo) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	// password and otp are deliberately left out of the projection
+	query := `SELECT id, uuid, user_name, email, phone, user_status, user_role, created_at, updated_at, deleted_at
+              FROM "user" WHERE 1=1`
+
+	query, args := applyUserFilter(query, filter, "@")
+
+	// fn's own errors go back untouched
+	var fnErr error
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, query, pgx.NamedArgs(args))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			u, err := pgx.RowToAddrOfStructByNameLax[domain.User](rows)
+			if err != nil {
+				return err
+			}
+			if fnErr = fn(u); fnErr != nil {
+				return fnErr
+			}
+		}
+		return rows.Err()
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return MapError(err)
+}
+
+// Update() updates an user entity, nil fields keep their value
+func (r *PgxUserRepo) Update(ctx context.Context, up domain.UserUpdate) error {
+	query := `
+        UPDATE "user"
+        SET
+            user_name = COALESCE(@user_name, user_name),
+            email = COALESCE(@email, email),
+            phone = COALESCE(@phone, phone),
+            user_status = COALESCE(@user_status, user_status),
+            updated_at = NOW()
+        WHERE uuid = @uuid AND deleted_at IS NULL`
+
+	return r.exec(ctx, query, pgx.NamedArgs{
+		"uuid":        up.UUID,
+		"user_name":   up.UserName,
+		"email":       up.Email,
+		"phone":       up.Phone,
+		"user_status": up.Status,
+	})
+}
+
+// SoftDelete() trashes a user, see UserRepo.SoftDelete
+func (r *PgxUserRepo) SoftDelete(ctx context.Context, id string) error {
+	return r.exec(ctx, `UPDATE "user" SET deleted_at = NOW(), status_before_delete = user_status, user_status = 'inactive'
+	          WHERE uuid = $1 AND deleted_at IS NULL`, id)
+}
+
+// Restore() restores a trashed user with the given status
+func (r *PgxUserRepo) Restore(ctx context.Context, id string, status string) error {
+	return r.exec(ctx, `UPDATE "user" SET deleted_at = NULL, status_before_delete = NULL, updated_at = NOW(), user_status = $2
+	          WHERE uuid = $1 AND deleted_at IS NOT NULL`, id, status)
+}
+
+// Trash() reads the trashed users
+func (r *PgxUserRepo) Trash(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `SELECT uuid, user_name, email, phone, user_status, created_at, updated_at, deleted_at
+              FROM "user"
+              WHERE deleted_at IS NOT NULL`
+
+	args := pgx.NamedArgs{}
+	if filter.UserName != "" {
+		query += ` AND user_name ILIKE @user_name`
+		args["user_name"] = "%" + filter.UserName + "%"
+	}
+	if filter.Email != "" {
+		query += ` AND email = @email`
+		args["email"] = filter.Email
+	}
+	if filter.Limit > 0 {
+		query += ` LIMIT @limit`
+		args["limit"] = filter.Limit
+	}
+	if filter.Offset > 0 {
+		query += ` OFFSET @offset`
+		args["offset"] = filter.Offset
+	}
+
+	return r.list(ctx, query, args)
+}
+
+// ListTrashedBefore() lists users that sit in the trash since before the cutoff
+func (r *PgxUserRepo) ListTrashedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error) {
+	query := `SELECT uuid, user_name, email, phone, user_status, created_at, updated_at, deleted_at
+              FROM "user"
+              WHERE deleted_at IS NOT NULL AND deleted_at < $1
+              ORDER BY deleted_at
+              LIMIT $2`
+
+	return r.list(ctx, query, cutoff, limit)
+}
+
+// Prune() hard deletes an user, only trashed users can be pruned
+func (r *PgxUserRepo) Prune(ctx context.Context, id string) error {
+	return r.exec(ctx, `DELETE FROM "user" WHERE uuid = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+func (r *PgxUserRepo) CheckConflict(ctx context.Context, username, email, phone string) ([]domain.ErrorItem, error) {
+	var usernameTaken, emailTaken, phoneTaken bool
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		return q.QueryRow(ctx, conflictQuery, username, email, phone).Scan(&usernameTaken, &emailTaken, &phoneTaken)
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+
+	return conflictItems(usernameTaken, emailTaken, phoneTaken), nil
+}
+
+func (r *PgxUserRepo) one(ctx context.Context, query string, args ...any) (*domain.User, error) {
+	var u *domain.User
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		u, err = pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[domain.User])
+		return err
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return u, nil
+}
+
+func (r *PgxUserRepo) list(ctx context.Context, query string, args ...any) ([]*domain.User, error) {
+	var users []*domain.User
+	err := pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		users, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[domain.User])
+		return err
+	})
+	if err != nil {
+		return nil, MapError(err)
+	}
+	return users, nil
+}
+
+func (r *PgxUserRepo) exec(ctx context.Context, query string, args ...any) error {
+	return MapError(pgxConn(ctx, r.pool, func(q pgxQuerier) error {
+		_, err := q.Exec(ctx, query, args...)
+		return err
+	}))
+}
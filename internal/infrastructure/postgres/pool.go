@@ -0,0 +1,75 @@
+// Package postgres
+// This one opens the native pgx pool
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+var execModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// ConnectPool opens a pgxpool for the Pgx repositories and waits for the database like ConnectDB does
+func ConnectPool(cfg Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.dsn())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+
+	mode, ok := execModes[cfg.StatementCacheMode]
+	if !ok {
+		return nil, fmt.Errorf("unknown statement cache mode %q", cfg.StatementCacheMode)
+	}
+	poolCfg.ConnConfig.DefaultQueryExecMode = mode
+	poolCfg.MaxConns = int32(cfg.PoolSize)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	poolCfg.MaxConnLifetime = 5 * time.Minute
+
+	// Payload maps go out as JSONB in the exec modes too, those don't ask the server for parameter types
+	poolCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		conn.TypeMap().RegisterDefaultPgType(map[string]any{}, "jsonb")
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pool: %w", err)
+	}
+
+	const maxRetries = 5
+	for i := 0; i < maxRetries; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = pool.Ping(ctx)
+		cancel()
+		if err == nil {
+			log.Println("✅ Successfully connected to PostgreSQL (pgxpool)")
+			return pool, nil
+		}
+
+		log.Printf("⚠️ Failed to ping database (attempt %d/%d): %v. Retrying in 2 seconds...", i+1, maxRetries, err)
+		time.Sleep(2 * time.Second)
+	}
+
+	pool.Close()
+	return nil, fmt.Errorf("failed to connect to PostgreSQL after %d retries", maxRetries)
+}
+
+// PoolDB exposes the pool as a *sql.DB for the sqlx repositories and the TxManager,
+// so the two kinds of repositories draw from one set of connections
+func PoolDB(pool *pgxpool.Pool) *sql.DB {
+	return stdlib.OpenDBFromPool(pool)
+}
@@ -0,0 +1,117 @@
+// Package postgres
+// This one spreads reads over the read replicas
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/jmoiron/sqlx"
+)
+
+// pingTimeout bounds one replica health check
+const pingTimeout = 2 * time.Second
+
+type replica struct {
+	name    string
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// ReadRouter hands out a connection for reads. Reads go round robin to the replicas that
+// passed their last health check, and to the primary when none did, inside a transaction or
+// when the context asks for primary reads.
+type ReadRouter struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// NewReadRouter routes over replicas, with none every read goes to primary.
+// Replicas start out unhealthy until HealthCheck has pinged them.
+func NewReadRouter(primary *sql.DB, replicas []*sql.DB) *ReadRouter {
+	rr := &ReadRouter{primary: sqlx.NewDb(primary, "pgx")}
+	for i, db := range replicas {
+		rr.replicas = append(rr.replicas, &replica{
+			name: "replica-" + strconv.Itoa(i),
+			db:   sqlx.NewDb(db, "pgx"),
+		})
+	}
+	return rr
+}
+
+// conn returns what a read made with ctx should query
+func (rr *ReadRouter) conn(ctx context.Context) dbtx {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok || domain.PrimaryReads(ctx) {
+		return conn(ctx, rr.primary)
+	}
+
+	n := len(rr.replicas)
+	start := rr.next.Add(1)
+	for i := 0; i < n; i++ {
+		if r := rr.replicas[(start+uint64(i))%uint64(n)]; r.healthy.Load() {
+			return dbtx{r.db}
+		}
+	}
+	return dbtx{rr.primary}
+}
+
+// HealthCheck returns a worker that pings every replica each interval and takes the ones
+// that don't answer out of rotation until they do
+func (rr *ReadRouter) HealthCheck(interval time.Duration) ports.BackgroundWorker {
+	return &replicaHealthCheck{router: rr, interval: interval}
+}
+
+type replicaHealthCheck struct {
+	router   *ReadRouter
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+func (w *replicaHealthCheck) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *replicaHealthCheck) check(ctx context.Context) {
+	for _, r := range w.router.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Only the flips are logged, a replica that stays down would flood the log otherwise
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			slog.Info("Read replica in rotation", "replica", r.name)
+		} else {
+			slog.Warn("Read replica out of rotation", "replica", r.name, "error", err)
+		}
+	}
+}
+
+func (w *replicaHealthCheck) Stop() { w.wg.Wait() }
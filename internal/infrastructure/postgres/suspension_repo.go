@@ -0,0 +1,89 @@
+// Package postgres
+// This one holds the user suspension history repository
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type SuspensionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSuspensionRepo(db *sql.DB) *SuspensionRepo {
+	return &SuspensionRepo{
+		db: sqlx.NewDb(db, "pgx"),
+	}
+}
+
+// Create() records an open suspension
+func (r *SuspensionRepo) Create(ctx context.Context, s *domain.Suspension) error {
+	query := `
+		INSERT INTO "user_suspension" (uuid, user_id, suspended_by, reason, expires_at)
+		VALUES (:uuid, :user_id, :suspended_by, :reason, :expires_at)
+		RETURNING id, suspended_at
+	`
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, s)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.StructScan(s)
+	}
+	return MapError(rows.Err())
+}
+
+// ReadActive() reads the currently open suspension of a user
+func (r *SuspensionRepo) ReadActive(ctx context.Context, userID string) (*domain.Suspension, error) {
+	s := &domain.Suspension{}
+	query := `SELECT * FROM "user_suspension" WHERE user_id = $1 AND lifted_at IS NULL`
+
+	if err := conn(ctx, r.db).GetContext(ctx, s, query, userID); err != nil {
+		return nil, MapError(err)
+	}
+	return s, nil
+}
+
+// Lift() closes an open suspension
+func (r *SuspensionRepo) Lift(ctx context.Context, id string, liftedBy *string, reason string) error {
+	query := `UPDATE "user_suspension" SET lifted_at = NOW(), lifted_by = $2, lift_reason = $3
+	          WHERE uuid = $1 AND lifted_at IS NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, liftedBy, reason)
+
+	return MapError(err)
+}
+
+// History() lists the suspensions of a user newest first
+func (r *SuspensionRepo) History(ctx context.Context, userID string) ([]*domain.Suspension, error) {
+	var list []*domain.Suspension
+	query := `SELECT * FROM "user_suspension" WHERE user_id = $1 ORDER BY suspended_at DESC`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &list, query, userID); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// ListExpired() lists open suspensions that are due for reinstatement
+func (r *SuspensionRepo) ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.Suspension, error) {
+	var list []*domain.Suspension
+	// Trashed users are skipped, they are picked up again once restored
+	query := `SELECT s.* FROM "user_suspension" s
+	          JOIN "user" u ON u.uuid = s.user_id AND u.deleted_at IS NULL
+	          WHERE s.lifted_at IS NULL AND s.expires_at IS NOT NULL AND s.expires_at <= $1
+	          ORDER BY s.expires_at
+	          LIMIT $2`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &list, query, now, limit); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,175 @@
+// Package postgres
+// This one runs several repository calls in one transaction
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// TxRetryPolicy is how often a transaction that hit a serialization failure or a deadlock is
+// run again. The delay doubles from BaseDelay up to MaxDelay, with jitter so the transactions
+// that collided don't collide again.
+type TxRetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+type txKey struct{}
+
+// txState is the transaction carried in the context. conn is the connection it runs on,
+// the pgx repositories borrow it to join the transaction.
+type txState struct {
+	conn        *sqlx.Conn
+	tx          *sqlx.Tx
+	afterCommit []func()
+}
+
+type TxManager struct {
+	db     *sqlx.DB
+	policy TxRetryPolicy
+}
+
+func NewTxManager(db *sql.DB, policy TxRetryPolicy) *TxManager {
+	return &TxManager{
+		db:     sqlx.NewDb(db, "pgx"),
+		policy: policy,
+	}
+}
+
+// WithinTx() runs fn in a transaction the repositories pick up from the context it gets.
+// Called inside another WithinTx it joins the outer transaction. fn runs again on a
+// serialization failure or deadlock, so it must not have effects outside the database.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	delay := m.policy.BaseDelay
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= m.policy.MaxRetries {
+			return err
+		}
+
+		wait := delay/2 + rand.N(delay/2+1)
+		slog.Warn("Transaction conflicted, retrying", "attempt", attempt+1, "retry_in", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, m.policy.MaxDelay)
+	}
+}
+
+// AfterCommit() runs fn once the transaction in ctx commits, or right away without one.
+// Nothing runs if the transaction rolls back.
+func (m *TxManager) AfterCommit(ctx context.Context, fn func()) {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		st.afterCommit = append(st.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+func (m *TxManager) InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+func (m *TxManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	c, err := m.db.Connx(ctx)
+	if err != nil {
+		return MapError(err)
+	}
+	defer c.Close()
+
+	tx, err := c.BeginTxx(ctx, nil)
+	if err != nil {
+		return MapError(err)
+	}
+	defer tx.Rollback()
+
+	st := &txState{conn: c, tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, st)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return MapError(err)
+	}
+
+	for _, f := range st.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// retryable reports whether the transaction failed only because it collided with another one
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	}
+	return false
+}
+
+// dbtx is what the repositories query, the transaction in the context or the pool
+type dbtx struct {
+	sqlx.ExtContext
+}
+
+// conn returns the transaction in ctx, or db when there is none
+func conn(ctx context.Context, db *sqlx.DB) dbtx {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return dbtx{st.tx}
+	}
+	return dbtx{db}
+}
+
+// inTx runs fn in the transaction in ctx, or in a transaction of its own when there is none
+func inTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(st.tx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return MapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return MapError(tx.Commit())
+}
+
+func (q dbtx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.GetContext(ctx, q.ExtContext, dest, query, args...)
+}
+
+func (q dbtx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.SelectContext(ctx, q.ExtContext, dest, query, args...)
+}
+
+func (q dbtx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return sqlx.NamedExecContext(ctx, q.ExtContext, query, arg)
+}
+
+func (q dbtx) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
+	return sqlx.NamedQueryContext(ctx, q.ExtContext, query, arg)
+}
@@ -5,19 +5,23 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/jmoiron/sqlx"
 )
 
+// UserRepo writes to the primary and reads through reads, which may answer from a replica
 type UserRepo struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	reads *ReadRouter
 }
 
-func NewUserRepo(db *sql.DB) *UserRepo {
+func NewUserRepo(db *sql.DB, reads *ReadRouter) *UserRepo {
 	// Wrap the standard *sql.DB into sqlx.DB
 	return &UserRepo{
-		db: sqlx.NewDb(db, "pgx"),
+		db:    sqlx.NewDb(db, "pgx"),
+		reads: reads,
 	}
 }
 
@@ -30,7 +34,7 @@ func (r *UserRepo) Create(ctx context.Context, u *domain.User) error {
 	`
 
 	// NamedQueryContext maps :user_name to u.UserName via tags
-	rows, err := r.db.NamedQueryContext(ctx, query, u)
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, u)
 	if err != nil {
 		return MapError(err)
 	}
@@ -48,7 +52,7 @@ func (r *UserRepo) ReadOne(ctx context.Context, id string) (*domain.User, error)
 	u := &domain.User{}
 	query := `SELECT * FROM "user" WHERE uuid = $1 AND deleted_at IS NULL`
 
-	err := r.db.GetContext(ctx, u, query, id)
+	err := r.reads.conn(ctx).GetContext(ctx, u, query, id)
 	if err != nil {
 		return nil, MapError(err)
 	}
@@ -59,7 +63,7 @@ func (r *UserRepo) ReadByEmail(ctx context.Context, email string) (*domain.User,
 	u := &domain.User{}
 	query := `SELECT * FROM "user" WHERE email = $1 AND deleted_at IS NULL`
 
-	err := r.db.GetContext(ctx, u, query, email)
+	err := r.reads.conn(ctx).GetContext(ctx, u, query, email)
 	if err != nil {
 		return nil, MapError(err)
 	}
@@ -75,7 +79,64 @@ func (r *UserRepo) ReadAll(ctx context.Context, filter domain.UserFilter) ([]*do
 	query := `SELECT id, uuid, user_name, email, phone, user_status, created_at, updated_at 
               FROM "user" WHERE 1=1`
 
-	//  Named arguments map for sqlx
+	query, args := applyUserFilter(query, filter, ":")
+
+	//  Execute using NamedQuery
+	rows, err := r.reads.conn(ctx).NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	defer rows.Close()
+
+	//  Scan results into the domain slice
+	for rows.Next() {
+		u := &domain.User{}
+		if err := rows.StructScan(u); err != nil {
+			return nil, MapError(err)
+		}
+		users = append(users, u)
+	}
+
+	// Check for errors during iteration
+	if err := rows.Err(); err != nil {
+		return nil, MapError(err)
+	}
+
+	return users, nil
+}
+
+// Stream() walks the filtered users one row at a time straight off the cursor
+// so large exports never hold the whole result set in memory
+func (r *UserRepo) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	// password and otp are deliberately left out of the projection
+	query := `SELECT id, uuid, user_name, email, phone, user_status, user_role, created_at, updated_at, deleted_at
+              FROM "user" WHERE 1=1`
+
+	query, args := applyUserFilter(query, filter, ":")
+
+	rows, err := r.reads.conn(ctx).NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u := &domain.User{}
+		if err := rows.StructScan(u); err != nil {
+			return MapError(err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return MapError(rows.Err())
+}
+
+// applyUserFilter appends the shared list filters and pagination to a base query.
+// mark starts the named parameters, ":" for sqlx and "@" for pgx.
+func applyUserFilter(query string, filter domain.UserFilter, mark string) (string, map[string]any) {
+	//  Named arguments map
 	args := make(map[string]any)
 
 	//  Apply Soft Delete filter
@@ -85,57 +146,39 @@ func (r *UserRepo) ReadAll(ctx context.Context, filter domain.UserFilter) ([]*do
 
 	//  Build Dynamic Filters
 	if filter.UserName != "" {
-		query += ` AND user_name ILIKE :user_name`
+		query += ` AND user_name ILIKE ` + mark + `user_name`
 		args["user_name"] = "%" + filter.UserName + "%"
 	}
 
 	if filter.Email != "" {
-		query += ` AND email = :email`
+		query += ` AND email = ` + mark + `email`
 		args["email"] = filter.Email
 	}
 
 	if filter.Phone != "" {
-		query += ` AND phone = :phone`
+		query += ` AND phone = ` + mark + `phone`
 		args["phone"] = filter.Phone
 	}
 
 	if filter.UserStatus != "" {
-		query += ` AND user_status = :user_status`
+		query += ` AND user_status = ` + mark + `user_status`
 		args["user_status"] = filter.UserStatus
 	}
 
+	// Stable ordering keeps pagination and exports deterministic
+	query += ` ORDER BY id`
+
 	//  Apply Pagination
 	if filter.Limit > 0 {
-		query += ` LIMIT :limit`
+		query += ` LIMIT ` + mark + `limit`
 		args["limit"] = filter.Limit
 	}
 	if filter.Offset > 0 {
-		query += ` OFFSET :offset`
+		query += ` OFFSET ` + mark + `offset`
 		args["offset"] = filter.Offset
 	}
 
-	//  Execute using NamedQuery
-	rows, err := r.db.NamedQueryContext(ctx, query, args)
-	if err != nil {
-		return nil, MapError(err)
-	}
-	defer rows.Close()
-
-	//  Scan results into the domain slice
-	for rows.Next() {
-		u := &domain.User{}
-		if err := rows.StructScan(u); err != nil {
-			return nil, MapError(err)
-		}
-		users = append(users, u)
-	}
-
-	// Check for errors during iteration
-	if err := rows.Err(); err != nil {
-		return nil, MapError(err)
-	}
-
-	return users, nil
+	return query, args
 }
 
 // Update() updates an user entity
@@ -150,7 +193,7 @@ func (r *UserRepo) Update(ctx context.Context, up domain.UserUpdate) error {
             updated_at = NOW()
         WHERE uuid = :uuid AND deleted_at IS NULL`
 
-	_, err := r.db.NamedExecContext(ctx, query, map[string]any{
+	_, err := conn(ctx, r.db).NamedExecContext(ctx, query, map[string]any{
 		"uuid":        up.UUID,
 		"user_name":   up.UserName,
 		"email":       up.Email,
@@ -162,17 +205,20 @@ func (r *UserRepo) Update(ctx context.Context, up domain.UserUpdate) error {
 }
 
 // SoftDelete() soft delets an user with status set to inactive and deleted_at date
+// the current status is kept in status_before_delete so Restore can bring it back
 func (r *UserRepo) SoftDelete(ctx context.Context, id string) error {
-	query := `UPDATE "user" SET deleted_at = NOW(), user_status = 'inactive' WHERE uuid = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `UPDATE "user" SET deleted_at = NOW(), status_before_delete = user_status, user_status = 'inactive'
+	          WHERE uuid = $1 AND deleted_at IS NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 
 	return MapError(err)
 }
 
-// Restore() restores a trashed user
-func (r *UserRepo) Restore(ctx context.Context, id string) error {
-	query := `UPDATE "user" SET deleted_at = NULL, updated_at = NOW(), user_status = 'active' WHERE uuid = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+// Restore() restores a trashed user with the given status
+func (r *UserRepo) Restore(ctx context.Context, id string, status string) error {
+	query := `UPDATE "user" SET deleted_at = NULL, status_before_delete = NULL, updated_at = NOW(), user_status = $2
+	          WHERE uuid = $1 AND deleted_at IS NOT NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, status)
 
 	return MapError(err)
 }
@@ -210,7 +256,7 @@ func (r *UserRepo) Trash(ctx context.Context, filter domain.UserFilter) ([]*doma
 	}
 
 	//  Execution
-	rows, err := r.db.NamedQueryContext(ctx, query, args)
+	rows, err := r.reads.conn(ctx).NamedQueryContext(ctx, query, args)
 	if err != nil {
 		return nil, MapError(err)
 	}
@@ -228,11 +274,26 @@ func (r *UserRepo) Trash(ctx context.Context, filter domain.UserFilter) ([]*doma
 	return users, nil
 }
 
+// ListTrashedBefore() lists users that sit in the trash since before the cutoff
+func (r *UserRepo) ListTrashedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	query := `SELECT uuid, user_name, email, phone, user_status, created_at, updated_at, deleted_at
+              FROM "user"
+              WHERE deleted_at IS NOT NULL AND deleted_at < $1
+              ORDER BY deleted_at
+              LIMIT $2`
+
+	if err := r.reads.conn(ctx).SelectContext(ctx, &users, query, cutoff, limit); err != nil {
+		return nil, MapError(err)
+	}
+	return users, nil
+}
+
 func (r *UserRepo) ReadOneDeleted(ctx context.Context, id string) (*domain.User, error) {
 	query := `SELECT * FROM "user" WHERE uuid = $1 AND deleted_at IS NOT NULL`
 	u := &domain.User{}
 
-	err := r.db.GetContext(ctx, u, query, id)
+	err := r.reads.conn(ctx).GetContext(ctx, u, query, id)
 	if err != nil {
 		return nil, MapError(err)
 	}
@@ -241,41 +302,45 @@ func (r *UserRepo) ReadOneDeleted(ctx context.Context, id string) (*domain.User,
 
 }
 
-// Prune() hard deletes an user
+// Prune() hard deletes an user, only trashed users can be pruned
 func (r *UserRepo) Prune(ctx context.Context, id string) error {
-	query := `DELETE FROM "user" WHERE uuid = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM "user" WHERE uuid = $1 AND deleted_at IS NOT NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	return MapError(err)
 }
 
 func (r *UserRepo) CheckConflict(ctx context.Context, username, email, phone string) ([]domain.ErrorItem, error) {
-	query := `
-		SELECT 
-			EXISTS(SELECT 1 FROM "user" WHERE user_name = $1 AND deleted_at IS NULL) as username_taken,
-			EXISTS(SELECT 1 FROM "user" WHERE email = $2 AND deleted_at IS NULL) as email_taken,
-			EXISTS(SELECT 1 FROM "user" WHERE phone = $3 AND deleted_at IS NULL) as phone_taken
-	`
-
 	var res struct {
 		UsernameTaken bool `db:"username_taken"`
 		EmailTaken    bool `db:"email_taken"`
 		PhoneTaken    bool `db:"phone_taken"`
 	}
 
-	if err := r.db.GetContext(ctx, &res, query, username, email, phone); err != nil {
+	// Checked on the primary, a lagging replica would miss a user registered a moment ago
+	if err := conn(ctx, r.db).GetContext(ctx, &res, conflictQuery, username, email, phone); err != nil {
 		return nil, MapError(err)
 	}
 
+	return conflictItems(res.UsernameTaken, res.EmailTaken, res.PhoneTaken), nil
+}
+
+const conflictQuery = `
+		SELECT 
+			EXISTS(SELECT 1 FROM "user" WHERE user_name = $1 AND deleted_at IS NULL) as username_taken,
+			EXISTS(SELECT 1 FROM "user" WHERE email = $2 AND deleted_at IS NULL) as email_taken,
+			EXISTS(SELECT 1 FROM "user" WHERE phone = $3 AND deleted_at IS NULL) as phone_taken
+	`
+
+func conflictItems(usernameTaken, emailTaken, phoneTaken bool) []domain.ErrorItem {
 	var conflicts []domain.ErrorItem
-	if res.UsernameTaken {
+	if usernameTaken {
 		conflicts = append(conflicts, domain.ErrorItem{Field: "user_name", Message: "username already taken"})
 	}
-	if res.EmailTaken {
+	if emailTaken {
 		conflicts = append(conflicts, domain.ErrorItem{Field: "email", Message: "email already registered"})
 	}
-	if res.PhoneTaken {
+	if phoneTaken {
 		conflicts = append(conflicts, domain.ErrorItem{Field: "phone", Message: "phone number in use"})
 	}
-
-	return conflicts, nil
+	return conflicts
 }
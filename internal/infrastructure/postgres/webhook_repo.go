@@ -0,0 +1,245 @@
+// Package postgres
+// This one holds the webhook subscription and delivery log repository
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepo struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepo(db *sql.DB) *WebhookRepo {
+	return &WebhookRepo{
+		db: sqlx.NewDb(db, "pgx"),
+	}
+}
+
+// webhookRow is the table shape, event_types is JSONB
+type webhookRow struct {
+	ID          int64     `db:"id"`
+	UUID        string    `db:"uuid"`
+	URL         string    `db:"url"`
+	EventTypes  []byte    `db:"event_types"`
+	Secret      string    `db:"secret"`
+	Description string    `db:"description"`
+	Active      bool      `db:"active"`
+	CreatedBy   *string   `db:"created_by"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (r webhookRow) toDomain() (*domain.Webhook, error) {
+	hook := &domain.Webhook{
+		ID:          r.ID,
+		UUID:        r.UUID,
+		URL:         r.URL,
+		Secret:      r.Secret,
+		Description: r.Description,
+		Active:      r.Active,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+	if r.CreatedBy != nil {
+		hook.CreatedBy = *r.CreatedBy
+	}
+	if err := json.Unmarshal(r.EventTypes, &hook.EventTypes); err != nil {
+		return nil, fmt.Errorf("webhook %s event types: %w", r.UUID, err)
+	}
+	return hook, nil
+}
+
+// Create() stores a webhook and fills in the generated columns
+func (r *WebhookRepo) Create(ctx context.Context, hook *domain.Webhook) error {
+	eventTypes, err := json.Marshal(hook.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO "webhook" (uuid, url, event_types, secret, description, active, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+	          RETURNING id, created_at, updated_at`
+
+	row := r.db.QueryRowxContext(ctx, query,
+		hook.UUID, hook.URL, string(eventTypes), hook.Secret, hook.Description, hook.Active, hook.CreatedBy)
+	return MapError(row.Scan(&hook.ID, &hook.CreatedAt, &hook.UpdatedAt))
+}
+
+// List() reads every webhook oldest first
+func (r *WebhookRepo) List(ctx context.Context) ([]*domain.Webhook, error) {
+	var rows []webhookRow
+	if err := r.db.SelectContext(ctx, &rows, `SELECT * FROM "webhook" ORDER BY id`); err != nil {
+		return nil, MapError(err)
+	}
+
+	list := make([]*domain.Webhook, 0, len(rows))
+	for _, row := range rows {
+		hook, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, hook)
+	}
+	return list, nil
+}
+
+func (r *WebhookRepo) ReadOne(ctx context.Context, id string) (*domain.Webhook, error) {
+	var row webhookRow
+	if err := r.db.GetContext(ctx, &row, `SELECT * FROM "webhook" WHERE uuid = $1`, id); err != nil {
+		return nil, MapError(err)
+	}
+	return row.toDomain()
+}
+
+// Update() writes only the fields that are set
+func (r *WebhookRepo) Update(ctx context.Context, u domain.WebhookUpdate) error {
+	sets := []string{"updated_at = NOW()"}
+	args := []any{u.UUID}
+
+	add := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if u.URL != nil {
+		add("url", *u.URL)
+	}
+	if u.EventTypes != nil {
+		eventTypes, err := json.Marshal(u.EventTypes)
+		if err != nil {
+			return err
+		}
+		add("event_types", string(eventTypes))
+	}
+	if u.Secret != nil {
+		add("secret", *u.Secret)
+	}
+	if u.Description != nil {
+		add("description", *u.Description)
+	}
+	if u.Active != nil {
+		add("active", *u.Active)
+	}
+
+	query := `UPDATE "webhook" SET ` + strings.Join(sets, ", ") + ` WHERE uuid = $1`
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return MapError(err)
+	}
+	return expectOne(res)
+}
+
+// Delete() removes the webhook along with its delivery log
+func (r *WebhookRepo) Delete(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM "webhook" WHERE uuid = $1`, id)
+	if err != nil {
+		return MapError(err)
+	}
+	return expectOne(res)
+}
+
+// CreateDeliveries() queues the deliveries in one statement, ones already queued are skipped
+func (r *WebhookRepo) CreateDeliveries(ctx context.Context, list []*domain.WebhookDelivery) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]any, len(list))
+	for i, d := range list {
+		// JSONB wants text, a raw []byte would be sent as bytea
+		rows[i] = map[string]any{
+			"uuid":       d.UUID,
+			"webhook_id": d.WebhookID,
+			"event_id":   d.EventID,
+			"event_type": d.EventType,
+			"payload":    string(d.Payload),
+		}
+	}
+
+	query := `INSERT INTO "webhook_delivery" (uuid, webhook_id, event_id, event_type, payload)
+	          VALUES (:uuid, :webhook_id, :event_id, :event_type, :payload)
+	          ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	_, err := r.db.NamedExecContext(ctx, query, rows)
+	return MapError(err)
+}
+
+// ClaimDeliveries() leases due deliveries by pushing their next attempt past the lease.
+// SKIP LOCKED lets several dispatchers claim disjoint batches at the same time.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	var list []*domain.WebhookDelivery
+	query := `UPDATE "webhook_delivery" SET next_attempt_at = NOW() + $2::interval
+	          WHERE id IN (
+	              SELECT d.id FROM "webhook_delivery" d
+	              JOIN "webhook" w ON w.uuid = d.webhook_id AND w.active
+	              WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+	              ORDER BY d.next_attempt_at, d.id
+	              LIMIT $1
+	              FOR UPDATE OF d SKIP LOCKED
+	          )
+	          RETURNING *`
+
+	if err := r.db.SelectContext(ctx, &list, query, limit, lease.String()); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// RecordAttempt() saves the outcome the dispatcher wrote on the delivery
+func (r *WebhookRepo) RecordAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	query := `UPDATE "webhook_delivery"
+	          SET status = :status, attempts = :attempts, next_attempt_at = :next_attempt_at,
+	              last_status_code = :last_status_code, last_error = :last_error, delivered_at = :delivered_at
+	          WHERE id = :id`
+
+	_, err := r.db.NamedExecContext(ctx, query, d)
+	return MapError(err)
+}
+
+// ListDeliveries() reads the delivery log of a webhook newest first
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	list := []*domain.WebhookDelivery{}
+	query := `SELECT * FROM "webhook_delivery"
+	          WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+	          ORDER BY id DESC
+	          LIMIT $3 OFFSET $4`
+
+	if err := r.db.SelectContext(ctx, &list, query, filter.WebhookID, filter.Status, filter.Limit, filter.Offset); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// Redeliver() resets the attempts so even a delivery that gave up gets the full retry schedule again
+func (r *WebhookRepo) Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error) {
+	d := &domain.WebhookDelivery{}
+	query := `UPDATE "webhook_delivery"
+	          SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+	          WHERE webhook_id = $1 AND uuid = $2
+	          RETURNING *`
+
+	if err := r.db.GetContext(ctx, d, query, webhookID, deliveryID); err != nil {
+		return nil, MapError(err)
+	}
+	return d, nil
+}
+
+// expectOne turns an update or delete that matched nothing into a not found error
+func expectOne(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return MapError(err)
+	}
+	if n == 0 {
+		return MapError(sql.ErrNoRows)
+	}
+	return nil
+}
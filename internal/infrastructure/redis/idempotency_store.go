@@ -0,0 +1,80 @@
+// Package redis
+// This one keeps idempotent responses in redis
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript deletes the lock only if it still holds our token, so an expired
+// lock taken over by another request is never released by the old holder
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+type IdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewIdempotencyStore(client *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{client: client}
+}
+
+// Get() returns nil, nil when nothing is stored under key
+func (s *IdempotencyStore) Get(ctx context.Context, key string) (*domain.IdempotentResponse, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &domain.IdempotentResponse{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *IdempotencyStore) Save(ctx context.Context, key string, res domain.IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// Lock() is a SET NX on key:lock holding a random token
+func (s *IdempotencyStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(b)
+	lockKey := key + ":lock"
+
+	acquired, err := s.client.SetNX(ctx, lockKey, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// Released even when the request context is already cancelled
+		if err := unlockScript.Run(context.Background(), s.client, []string{lockKey}, token).Err(); err != nil {
+			slog.Error("Idempotency lock release failed", "key", lockKey, "error", err)
+		}
+	}
+	return unlock, true, nil
+}
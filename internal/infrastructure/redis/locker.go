@@ -0,0 +1,78 @@
+// Package redis
+// This one implements the Locker port on redis
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript is SET NX PX that hands out the next fencing token as the lock value.
+// The counter never expires so tokens keep increasing across holders.
+var acquireScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], token, "PX", ARGV[1])
+return token`)
+
+// renewScript extends the lock only while it still holds our token
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// Locker holds each lock as a key that expires, a holder that dies stops renewing and loses it
+type Locker struct {
+	client *redis.Client
+}
+
+func NewLocker(client *redis.Client) *Locker {
+	return &Locker{client: client}
+}
+
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (ports.Lock, bool, error) {
+	// The braces keep the lock and its counter in the same slot on a cluster
+	lockKey := "lock:{" + key + "}"
+	fenceKey := lockKey + ":fence"
+
+	token, err := acquireScript.Run(ctx, l.client, []string{lockKey, fenceKey}, ttl.Milliseconds()).Uint64()
+	if err != nil {
+		return nil, false, err
+	}
+	if token == 0 {
+		return nil, false, nil
+	}
+
+	return &lock{client: l.client, key: lockKey, token: token}, true, nil
+}
+
+type lock struct {
+	client *redis.Client
+	key    string
+	token  uint64
+}
+
+func (l *lock) Token() uint64 { return l.token }
+
+func (l *lock) Renew(ctx context.Context, ttl time.Duration) error {
+	ok, err := renewScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return domain.ErrLockLost
+	}
+	return nil
+}
+
+// Release() leaves a lock that was taken over alone
+func (l *lock) Release(ctx context.Context) error {
+	return unlockScript.Run(context.WithoutCancel(ctx), l.client, []string{l.key}, l.token).Err()
+}
@@ -0,0 +1,75 @@
+// Package redis
+// This one is the cluster wide rate limiter
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript keeps the theoretical arrival time of the next request in microseconds under the key.
+// The clock is redis' own so replicas with skewed clocks still agree.
+// ARGV: limit, interval in microseconds. Returns allowed, remaining, retry after and reset after in microseconds.
+var gcraScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - interval * limit
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+
+redis.call("SET", KEYS[1], new_tat, "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}`)
+
+type RateLimiter struct {
+	client   *redis.Client
+	fallback ports.RateLimiter
+	degraded atomic.Bool
+}
+
+// NewRateLimiter counts in redis and switches to fallback while redis fails, nil fails open instead
+func NewRateLimiter(client *redis.Client, fallback ports.RateLimiter) *RateLimiter {
+	return &RateLimiter{client: client, fallback: fallback}
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	interval := limit.Interval().Microseconds()
+	res, err := gcraScript.Run(ctx, l.client, []string{key}, limit.Limit, interval).Int64Slice()
+	if err != nil {
+		// Logged once per outage, not once per request
+		if !l.degraded.Swap(true) {
+			slog.Warn("Rate limiter lost redis, limiting in memory", "error", err)
+		}
+		if l.fallback == nil {
+			return domain.RateLimitResult{Allowed: true, Limit: limit.Limit, Remaining: limit.Limit}, nil
+		}
+		return l.fallback.Allow(ctx, key, limit)
+	}
+	if l.degraded.Swap(false) {
+		slog.Info("Rate limiter back on redis")
+	}
+
+	return domain.RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/cachetest"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisCacheBehavior(t *testing.T) {
+	var server *miniredis.Miniredis
+	cachetest.Run(t, cachetest.Backend{
+		Open: func(t *testing.T) ports.CacheRepo {
+			server = miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return NewRedisAdapter(client)
+		},
+		Advance: func(t *testing.T, d time.Duration) { server.FastForward(d) },
+	})
+}
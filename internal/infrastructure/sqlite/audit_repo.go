@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditRepo keeps the same hash chain as postgres, the IMMEDIATE transaction
+// takes the place of locking the head row
+type AuditRepo struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{
+		db: sqlx.NewDb(db, bindName),
+	}
+}
+
+// auditRow is the raw shape of an audit_log row, the payload is JSON text
+type auditRow struct {
+	ID        int64     `db:"id"`
+	UUID      string    `db:"uuid"`
+	EventType string    `db:"event_type"`
+	ActorID   *string   `db:"actor_id"`
+	Payload   *string   `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+	ChainSeq  *int64    `db:"chain_seq"`
+	PrevHash  *string   `db:"prev_hash"`
+	Hash      *string   `db:"hash"`
+}
+
+func (r auditRow) toDomain() (*domain.Audit, error) {
+	a := &domain.Audit{
+		ID:        r.ID,
+		UUID:      r.UUID,
+		EventType: r.EventType,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.ActorID != nil {
+		a.ActorID = *r.ActorID
+	}
+	if r.ChainSeq != nil {
+		a.ChainSeq = *r.ChainSeq
+	}
+	if r.PrevHash != nil {
+		a.PrevHash = *r.PrevHash
+	}
+	if r.Hash != nil {
+		a.Hash = *r.Hash
+	}
+	if r.Payload != nil {
+		if err := json.Unmarshal([]byte(*r.Payload), &a.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+type chainHeadRow struct {
+	LastSeq       int64      `db:"last_seq"`
+	LastHash      string     `db:"last_hash"`
+	LastCreatedAt *time.Time `db:"last_created_at"`
+}
+
+func (r *AuditRepo) Create(ctx context.Context, auditLog domain.Audit) error {
+	return r.CreateBatch(ctx, []domain.Audit{auditLog})
+}
+
+// CreateBatch() chains and inserts the entries in one transaction, the caller's if there is one.
+// Entries whose uuid is already stored, or repeated within the batch, are skipped.
+func (r *AuditRepo) CreateBatch(ctx context.Context, logs []domain.Audit) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	return inTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var head chainHeadRow
+		if err := tx.GetContext(ctx, &head, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1`); err != nil {
+			return MapError(err)
+		}
+
+		seen := make(map[string]bool, len(logs))
+		for _, l := range logs {
+			// The transaction holds the write lock, nothing can store the uuid between the check and the insert
+			var stored bool
+			if err := tx.GetContext(ctx, &stored, `SELECT EXISTS (SELECT 1 FROM audit_log WHERE uuid = ?)`, l.UUID); err != nil {
+				return MapError(err)
+			}
+			if stored || seen[l.UUID] {
+				continue
+			}
+			seen[l.UUID] = true
+
+			a, err := sealChained(&head, l)
+			if err != nil {
+				return err
+			}
+			payload, err := json.Marshal(a.Payload)
+			if err != nil {
+				return err
+			}
+
+			// System events (e.g. scheduled jobs) have no actor, store those as NULL
+			_, err = tx.ExecContext(ctx, `INSERT INTO audit_log
+				(uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash)
+				VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?)`,
+				a.UUID, a.EventType, a.ActorID, string(payload), a.CreatedAt, a.ChainSeq, a.PrevHash, a.Hash)
+			if err != nil {
+				return MapError(err)
+			}
+		}
+
+		_, err := tx.ExecContext(ctx,
+			`UPDATE audit_chain_head SET last_seq = ?, last_hash = ?, last_created_at = ? WHERE id = 1`,
+			head.LastSeq, head.LastHash, head.LastCreatedAt)
+		return MapError(err)
+	})
+}
+
+// sealChained links one entry to the head and advances the head in memory
+func sealChained(head *chainHeadRow, a domain.Audit) (domain.Audit, error) {
+	// Microseconds is what the column keeps, the hash has to match what is read back
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if head.LastCreatedAt != nil && now.Before(*head.LastCreatedAt) {
+		now = *head.LastCreatedAt
+	}
+
+	a.CreatedAt = now
+	a.ChainSeq = head.LastSeq + 1
+	a.PrevHash = head.LastHash
+
+	hash, err := domain.ChainHash(a.PrevHash, a)
+	if err != nil {
+		return a, err
+	}
+	a.Hash = hash
+
+	head.LastSeq = a.ChainSeq
+	head.LastHash = a.Hash
+	head.LastCreatedAt = &now
+	return a, nil
+}
+
+func (r *AuditRepo) Head(ctx context.Context) (domain.ChainHead, error) {
+	var head chainHeadRow
+	if err := conn(ctx, r.db).GetContext(ctx, &head, `SELECT last_seq, last_hash, last_created_at FROM audit_chain_head WHERE id = 1`); err != nil {
+		return domain.ChainHead{}, MapError(err)
+	}
+
+	h := domain.ChainHead{Seq: head.LastSeq, Hash: head.LastHash}
+	if head.LastCreatedAt != nil {
+		h.CreatedAt = *head.LastCreatedAt
+	}
+	return h, nil
+}
+
+func (r *AuditRepo) Walk(ctx context.Context, from, to time.Time, fn func(*domain.Audit) error) error {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at, chain_seq, prev_hash, hash
+              FROM audit_log
+              WHERE created_at >= ? AND created_at < ? AND chain_seq IS NOT NULL
+              ORDER BY chain_seq`
+
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, from, to)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row auditRow
+		if err := rows.StructScan(&row); err != nil {
+			return MapError(err)
+		}
+		a, err := row.toDomain()
+		if err != nil {
+			return err
+		}
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return MapError(rows.Err())
+}
+
+func (r *AuditRepo) CreateCheckpoint(ctx context.Context, cp *domain.Checkpoint) error {
+	query := `INSERT INTO audit_checkpoint (chain_seq, chain_hash, chain_created_at, signature)
+	          VALUES (:chain_seq, :chain_hash, :chain_created_at, :signature)
+	          RETURNING id, created_at`
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, cp)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.StructScan(cp)
+	}
+	return MapError(rows.Err())
+}
+
+func (r *AuditRepo) LatestCheckpoint(ctx context.Context) (*domain.Checkpoint, error) {
+	cp := &domain.Checkpoint{}
+	if err := conn(ctx, r.db).GetContext(ctx, cp, `SELECT * FROM audit_checkpoint ORDER BY chain_seq DESC LIMIT 1`); err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+func (r *AuditRepo) CheckpointBefore(ctx context.Context, at time.Time) (*domain.Checkpoint, error) {
+	cp := &domain.Checkpoint{}
+	query := `SELECT * FROM audit_checkpoint WHERE chain_created_at <= ? ORDER BY chain_seq DESC LIMIT 1`
+	if err := conn(ctx, r.db).GetContext(ctx, cp, query, at); err != nil {
+		return nil, MapError(err)
+	}
+	return cp, nil
+}
+
+func (r *AuditRepo) Checkpoints(ctx context.Context, from, to time.Time) ([]*domain.Checkpoint, error) {
+	var list []*domain.Checkpoint
+	query := `SELECT * FROM audit_checkpoint WHERE chain_created_at >= ? AND chain_created_at < ? ORDER BY chain_seq`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &list, query, from, to); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
+
+// Query() reads a page of audit entries newest first using keyset pagination
+func (r *AuditRepo) Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.Audit, error) {
+	query := `SELECT id, uuid, event_type, actor_id, payload, created_at
+              FROM audit_log
+              WHERE created_at >= :from AND created_at < :to`
+
+	args := map[string]any{
+		"from":  filter.From,
+		"to":    filter.To,
+		"limit": filter.Limit,
+	}
+
+	if filter.ActorID != "" {
+		query += ` AND actor_id = :actor_id`
+		args["actor_id"] = filter.ActorID
+	}
+
+	if filter.EventType != "" {
+		query += ` AND event_type = :event_type`
+		args["event_type"] = filter.EventType
+	}
+
+	if filter.PayloadKey != "" {
+		// See domain.PayloadMatch, the key is quoted into a JSON path. json_extract gives numbers
+		// back as numbers, so 2 and 2.0 compare equal, and booleans as 1 and 0, hence json_type.
+		m := domain.NewPayloadMatch(filter.PayloadValue)
+		query += ` AND (
+			(json_type(payload, :payload_path) = 'text' AND json_extract(payload, :payload_path) = :payload_text)
+			OR (json_type(payload, :payload_path) IN ('integer', 'real') AND json_extract(payload, :payload_path) = :payload_number)
+			OR json_type(payload, :payload_path) = :payload_bool)`
+		args["payload_path"] = `$."` + strings.ReplaceAll(filter.PayloadKey, `"`, `\"`) + `"`
+		args["payload_text"] = m.Text
+		args["payload_number"], args["payload_bool"] = nil, nil
+		if m.Number != nil {
+			f, _ := m.Number.Float64()
+			args["payload_number"] = f
+		}
+		if m.Bool != nil {
+			args["payload_bool"] = strconv.FormatBool(*m.Bool)
+		}
+	}
+
+	if filter.After != nil {
+		query += ` AND (created_at, id) < (:after_created_at, :after_id)`
+		args["after_created_at"] = filter.After.CreatedAt
+		args["after_id"] = filter.After.ID
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC LIMIT :limit`
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return nil, MapError(err)
+	}
+	defer rows.Close()
+
+	var list []*domain.Audit
+	for rows.Next() {
+		var row auditRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, MapError(err)
+		}
+		a, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, MapError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,35 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/contract"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/sqlite"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "contract.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	audit := sqlite.NewAuditRepo(db)
+	repos := contract.Repos{
+		Users:       sqlite.NewUserRepo(db),
+		Suspensions: sqlite.NewSuspensionRepo(db),
+		Audit:       audit,
+		AuditChain:  audit,
+		Tx:          sqlite.NewTxManager(db),
+	}
+
+	for _, c := range contract.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := c.Run(context.Background(), repos); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package sqlite
+// sqlite package holds a single file database for local development, no docker needed
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open opens (or creates) the database file at path and brings its schema up to date.
+//
+// Time columns hold unix microseconds so they sort and compare like postgres timestamps,
+// and transactions begin IMMEDIATE so writers queue on busy_timeout instead of deadlocking
+// when a reader upgrades.
+func Open(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)" +
+		"&_txlock=immediate&_time_integer_format=unix_micro&_inttotime=1"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// An in-memory database lives and dies with its connection
+	if path == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Printf("✅ Opened SQLite database %s", path)
+	return db, nil
+}
+
+// migrate applies the Up section of every embedded goose migration that has not run yet
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migration (version TEXT PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("sqlite migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".sql")
+
+		var applied int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migration WHERE version = ?`, version).Scan(&applied); err != nil {
+			return fmt.Errorf("sqlite migration %s: %w", version, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		src, err := migrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		up, _, _ := strings.Cut(string(src), "-- +goose Down")
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, up); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migration (version) VALUES (?)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite migration %s: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Applied SQLite migration %s", version)
+	}
+	return nil
+}
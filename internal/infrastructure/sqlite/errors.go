@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLite names the column in the message, e.g. "UNIQUE constraint failed: user.email"
+var uniqueRegex = regexp.MustCompile(`UNIQUE constraint failed: [^.\s]+\.([^,\s]+)`)
+
+// MapError does for the sqlite repositories what postgres.MapError does for postgres
+func MapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "the requested resource was not found",
+			Err:     err,
+		}
+	}
+
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		slog.Error("Database execution failed", "code", liteErr.Code(), "message", liteErr.Error())
+
+		switch liteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return handleConflict(liteErr)
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return &domain.AppError{
+				Code:    domain.CodeValidation,
+				Message: "this operation references a record that does not exist",
+				Err:     err,
+			}
+		case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+			return &domain.AppError{
+				Code:    domain.CodeValidation,
+				Message: "missing required information",
+				Err:     err,
+			}
+		}
+	}
+
+	slog.Error("DATA LAYER ERR", ":", err)
+	return &domain.AppError{
+		Code:    domain.CodeInternal,
+		Message: "a data layer error occurred",
+		Err:     err,
+	}
+}
+
+func handleConflict(liteErr *sqlite.Error) error {
+	match := uniqueRegex.FindStringSubmatch(liteErr.Error())
+	field := "resource"
+	if len(match) > 1 {
+		field = match[1]
+	}
+
+	return &domain.AppError{
+		Code:    domain.CodeConflict,
+		Message: fmt.Sprintf("%s is already in use", field),
+		Field:   field,
+		Err:     liteErr,
+	}
+}
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Timestamps are unix microseconds, the driver turns DATETIME columns back into time.Time
+CREATE TABLE IF NOT EXISTS "user"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid TEXT UNIQUE NOT NULL,
+
+  user_name TEXT UNIQUE NOT NULL,
+  email TEXT UNIQUE NOT NULL,
+  phone TEXT UNIQUE NOT NULL,
+  password TEXT NOT NULL,
+  user_status TEXT NOT NULL DEFAULT 'active' CHECK (user_status IN ('active', 'inactive', 'suspended')),
+  user_role TEXT NOT NULL DEFAULT 'user' CHECK (user_role IN ('admin', 'user', 'moderator')),
+  status_before_delete TEXT DEFAULT NULL CHECK (status_before_delete IN ('active', 'inactive', 'suspended')),
+
+  otp TEXT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  updated_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+
+  deleted_at DATETIME DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user__deleted_at ON "user" (deleted_at);
+
+CREATE TABLE IF NOT EXISTS "user_suspension"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid TEXT UNIQUE NOT NULL,
+
+  user_id TEXT NOT NULL REFERENCES "user" (uuid) ON DELETE CASCADE,
+  suspended_by TEXT NOT NULL,
+  reason TEXT NOT NULL,
+
+  suspended_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  expires_at DATETIME DEFAULT NULL,
+
+  lifted_at DATETIME DEFAULT NULL,
+  lifted_by TEXT DEFAULT NULL,
+  lift_reason TEXT DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_suspension__user_id ON "user_suspension" (user_id, suspended_at DESC);
+-- A user can only have one open suspension at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_suspension_unique_open ON "user_suspension" (user_id) WHERE lifted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "user_suspension";
+DROP TABLE IF EXISTS "user";
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One plain table, there are no partitions to archive in a development database
+CREATE TABLE IF NOT EXISTS "audit_log"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  actor_id TEXT,
+  payload TEXT,
+  created_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  chain_seq INTEGER DEFAULT NULL,
+  prev_hash TEXT DEFAULT NULL,
+  hash TEXT DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log__created_at ON "audit_log" (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log__chain_seq ON "audit_log" (created_at, chain_seq) WHERE chain_seq IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS "audit_chain_head"(
+  id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+  last_seq INTEGER NOT NULL DEFAULT 0,
+  last_hash TEXT NOT NULL DEFAULT '',
+  last_created_at DATETIME DEFAULT NULL
+);
+INSERT INTO "audit_chain_head" (id) VALUES (1) ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "audit_checkpoint"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  chain_seq INTEGER NOT NULL,
+  chain_hash TEXT NOT NULL,
+  chain_created_at DATETIME NOT NULL,
+  signature TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoint__chain_created_at ON "audit_checkpoint" (chain_created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "audit_checkpoint";
+DROP TABLE IF EXISTS "audit_chain_head";
+DROP TABLE IF EXISTS "audit_log";
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "webhook"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid TEXT UNIQUE NOT NULL,
+
+  url TEXT NOT NULL,
+  -- JSON array of event type patterns
+  event_types TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_by TEXT DEFAULT NULL,
+  created_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  updated_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_delivery"(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid TEXT UNIQUE NOT NULL,
+
+  webhook_id TEXT NOT NULL REFERENCES "webhook" (uuid) ON DELETE CASCADE,
+  event_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  payload BLOB NOT NULL,
+
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  last_status_code INTEGER DEFAULT NULL,
+  last_error TEXT DEFAULT NULL,
+
+  created_at DATETIME NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER)),
+  delivered_at DATETIME DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery__event ON "webhook_delivery" (webhook_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery__pending ON "webhook_delivery" (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery__log ON "webhook_delivery" (webhook_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "webhook_delivery";
+DROP TABLE IF EXISTS "webhook";
+-- +goose StatementEnd
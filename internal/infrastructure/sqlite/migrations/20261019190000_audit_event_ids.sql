@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Redelivered and replayed events must not be stored twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log__uuid ON "audit_log" (uuid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_log__uuid;
+-- +goose StatementEnd
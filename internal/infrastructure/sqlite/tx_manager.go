@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// bindName is the driver name sqlx knows the ? placeholder by
+const bindName = "sqlite3"
+
+type txKey struct{}
+
+type txState struct {
+	tx          *sqlx.Tx
+	afterCommit []func()
+}
+
+// TxManager needs no retries, SQLite has one writer at a time and the others
+// wait for it on busy_timeout
+type TxManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{
+		db: sqlx.NewDb(db, bindName),
+	}
+}
+
+// WithinTx() runs fn in a transaction the repositories pick up from the context,
+// joining the outer one when called inside another WithinTx
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return MapError(err)
+	}
+	defer tx.Rollback()
+
+	st := &txState{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, st)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return MapError(err)
+	}
+
+	for _, f := range st.afterCommit {
+		f()
+	}
+	return nil
+}
+
+func (m *TxManager) AfterCommit(ctx context.Context, fn func()) {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		st.afterCommit = append(st.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+func (m *TxManager) InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// dbtx is what the repositories query, the transaction in the context or the database
+type dbtx struct {
+	sqlx.ExtContext
+}
+
+func conn(ctx context.Context, db *sqlx.DB) dbtx {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return dbtx{st.tx}
+	}
+	return dbtx{db}
+}
+
+// inTx runs fn in the transaction in ctx, or in a transaction of its own when there is none
+func inTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(st.tx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return MapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return MapError(tx.Commit())
+}
+
+func (q dbtx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.GetContext(ctx, q.ExtContext, dest, query, args...)
+}
+
+func (q dbtx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return sqlx.SelectContext(ctx, q.ExtContext, dest, query, args...)
+}
+
+func (q dbtx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return sqlx.NamedExecContext(ctx, q.ExtContext, query, arg)
+}
+
+func (q dbtx) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
+	return sqlx.NamedQueryContext(ctx, q.ExtContext, query, arg)
+}
@@ -0,0 +1,272 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserRepo struct {
+	db *sqlx.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{
+		db: sqlx.NewDb(db, bindName),
+	}
+}
+
+func (r *UserRepo) Create(ctx context.Context, u *domain.User) error {
+	query := `
+		INSERT INTO "user" (uuid, user_name, email, user_role, phone, password)
+		VALUES (:uuid, :user_name, :email, :user_role, :phone, :password)
+		RETURNING id, user_status, created_at, updated_at
+	`
+
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, u)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.StructScan(u)
+	}
+	return MapError(rows.Err())
+}
+
+func (r *UserRepo) ReadOne(ctx context.Context, id string) (*domain.User, error) {
+	u := &domain.User{}
+	query := `SELECT * FROM "user" WHERE uuid = ? AND deleted_at IS NULL`
+
+	if err := conn(ctx, r.db).GetContext(ctx, u, query, id); err != nil {
+		return nil, MapError(err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) ReadByEmail(ctx context.Context, email string) (*domain.User, error) {
+	u := &domain.User{}
+	query := `SELECT * FROM "user" WHERE email = ? AND deleted_at IS NULL`
+
+	if err := conn(ctx, r.db).GetContext(ctx, u, query, email); err != nil {
+		return nil, MapError(err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) ReadAll(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `SELECT id, uuid, user_name, email, phone, user_status, created_at, updated_at
+              FROM "user" WHERE 1=1`
+
+	query, args := applyUserFilter(query, filter)
+
+	var users []*domain.User
+	err := r.each(ctx, query, args, func(u *domain.User) error {
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Stream() walks the filtered users one row at a time, secrets are left out of the projection
+func (r *UserRepo) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	query := `SELECT id, uuid, user_name, email, phone, user_status, user_role, created_at, updated_at, deleted_at
+              FROM "user" WHERE 1=1`
+
+	query, args := applyUserFilter(query, filter)
+	return r.each(ctx, query, args, fn)
+}
+
+// each runs a named query and hands every row to fn, fn's own errors come back untouched
+func (r *UserRepo) each(ctx context.Context, query string, args map[string]any, fn func(*domain.User) error) error {
+	rows, err := conn(ctx, r.db).NamedQueryContext(ctx, query, args)
+	if err != nil {
+		return MapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u := &domain.User{}
+		if err := rows.StructScan(u); err != nil {
+			return MapError(err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return MapError(rows.Err())
+}
+
+// applyUserFilter is postgres' list filter in SQLite, whose LIKE already ignores case
+func applyUserFilter(query string, filter domain.UserFilter) (string, map[string]any) {
+	args := make(map[string]any)
+
+	if !filter.ShowDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+	if filter.UserName != "" {
+		query += ` AND user_name LIKE :user_name`
+		args["user_name"] = "%" + filter.UserName + "%"
+	}
+	if filter.Email != "" {
+		query += ` AND email = :email`
+		args["email"] = filter.Email
+	}
+	if filter.Phone != "" {
+		query += ` AND phone = :phone`
+		args["phone"] = filter.Phone
+	}
+	if filter.UserStatus != "" {
+		query += ` AND user_status = :user_status`
+		args["user_status"] = filter.UserStatus
+	}
+
+	return paginate(query+` ORDER BY id`, filter, args)
+}
+
+// paginate appends LIMIT and OFFSET, SQLite only takes an OFFSET after a LIMIT so -1 stands for none
+func paginate(query string, filter domain.UserFilter, args map[string]any) (string, map[string]any) {
+	if filter.Limit <= 0 && filter.Offset <= 0 {
+		return query, args
+	}
+
+	args["limit"] = -1
+	if filter.Limit > 0 {
+		args["limit"] = filter.Limit
+	}
+	args["offset"] = max(filter.Offset, 0)
+	return query + ` LIMIT :limit OFFSET :offset`, args
+}
+
+func (r *UserRepo) Update(ctx context.Context, up domain.UserUpdate) error {
+	query := `
+        UPDATE "user"
+        SET
+            user_name = COALESCE(:user_name, user_name),
+            email = COALESCE(:email, email),
+            phone = COALESCE(:phone, phone),
+            user_status = COALESCE(:user_status, user_status),
+            updated_at = :now
+        WHERE uuid = :uuid AND deleted_at IS NULL`
+
+	_, err := conn(ctx, r.db).NamedExecContext(ctx, query, map[string]any{
+		"uuid":        up.UUID,
+		"user_name":   up.UserName,
+		"email":       up.Email,
+		"phone":       up.Phone,
+		"user_status": up.Status,
+		"now":         time.Now(),
+	})
+
+	return MapError(err)
+}
+
+func (r *UserRepo) SoftDelete(ctx context.Context, id string) error {
+	query := `UPDATE "user" SET deleted_at = ?, status_before_delete = user_status, user_status = 'inactive'
+	          WHERE uuid = ? AND deleted_at IS NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+
+	return MapError(err)
+}
+
+func (r *UserRepo) Restore(ctx context.Context, id string, status string) error {
+	query := `UPDATE "user" SET deleted_at = NULL, status_before_delete = NULL, updated_at = ?, user_status = ?
+	          WHERE uuid = ? AND deleted_at IS NOT NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), status, id)
+
+	return MapError(err)
+}
+
+func (r *UserRepo) Trash(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `SELECT uuid, user_name, email, phone, user_status, created_at, updated_at, deleted_at
+              FROM "user"
+              WHERE deleted_at IS NOT NULL`
+
+	args := make(map[string]any)
+	if filter.UserName != "" {
+		query += ` AND user_name LIKE :user_name`
+		args["user_name"] = "%" + filter.UserName + "%"
+	}
+	if filter.Email != "" {
+		query += ` AND email = :email`
+		args["email"] = filter.Email
+	}
+	query, args = paginate(query+` ORDER BY id`, filter, args)
+
+	var users []*domain.User
+	err := r.each(ctx, query, args, func(u *domain.User) error {
+		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepo) ListTrashedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	query := `SELECT uuid, user_name, email, phone, user_status, created_at, updated_at, deleted_at
+              FROM "user"
+              WHERE deleted_at IS NOT NULL AND deleted_at < ?
+              ORDER BY deleted_at
+              LIMIT ?`
+
+	if err := conn(ctx, r.db).SelectContext(ctx, &users, query, cutoff, limit); err != nil {
+		return nil, MapError(err)
+	}
+	return users, nil
+}
+
+func (r *UserRepo) ReadOneDeleted(ctx context.Context, id string) (*domain.User, error) {
+	u := &domain.User{}
+	query := `SELECT * FROM "user" WHERE uuid = ? AND deleted_at IS NOT NULL`
+
+	if err := conn(ctx, r.db).GetContext(ctx, u, query, id); err != nil {
+		return nil, MapError(err)
+	}
+	return u, nil
+}
+
+// Prune() hard deletes a trashed user, the suspension history goes with it
+func (r *UserRepo) Prune(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM "user" WHERE uuid = ? AND deleted_at IS NOT NULL`, id)
+	return MapError(err)
+}
+
+func (r *UserRepo) CheckConflict(ctx context.Context, username, email, phone string) ([]domain.ErrorItem, error) {
+	var res struct {
+		UsernameTaken bool `db:"username_taken"`
+		EmailTaken    bool `db:"email_taken"`
+		PhoneTaken    bool `db:"phone_taken"`
+	}
+
+	query := `
+		SELECT
+			EXISTS(SELECT 1 FROM "user" WHERE user_name = ? AND deleted_at IS NULL) AS username_taken,
+			EXISTS(SELECT 1 FROM "user" WHERE email = ? AND deleted_at IS NULL) AS email_taken,
+			EXISTS(SELECT 1 FROM "user" WHERE phone = ? AND deleted_at IS NULL) AS phone_taken
+	`
+	if err := conn(ctx, r.db).GetContext(ctx, &res, query, username, email, phone); err != nil {
+		return nil, MapError(err)
+	}
+
+	var conflicts []domain.ErrorItem
+	if res.UsernameTaken {
+		conflicts = append(conflicts, domain.ErrorItem{Field: "user_name", Message: "username already taken"})
+	}
+	if res.EmailTaken {
+		conflicts = append(conflicts, domain.ErrorItem{Field: "email", Message: "email already registered"})
+	}
+	if res.PhoneTaken {
+		conflicts = append(conflicts, domain.ErrorItem{Field: "phone", Message: "phone number in use"})
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"user.created"}`)
+	sig := Sign("whsec_test", 1700000000, body)
+
+	if !strings.HasPrefix(sig, "v1=") {
+		t.Fatalf("signature %q has no version prefix", sig)
+	}
+	if !Verify("whsec_test", 1700000000, body, sig) {
+		t.Fatal("signature does not verify")
+	}
+
+	for name, ok := range map[string]bool{
+		"other secret":    Verify("whsec_other", 1700000000, body, sig),
+		"other timestamp": Verify("whsec_test", 1700000001, body, sig),
+		"other body":      Verify("whsec_test", 1700000000, []byte(`{"type":"user.deleted"}`), sig),
+		"empty signature": Verify("whsec_test", 1700000000, body, ""),
+	} {
+		if ok {
+			t.Errorf("%s verified", name)
+		}
+	}
+}
+
+func TestSendSignsTheDelivery(t *testing.T) {
+	hook := &domain.Webhook{Secret: "whsec_test"}
+	d := &domain.WebhookDelivery{UUID: "delivery-1", EventType: "user.created", Payload: []byte(`{"id":"1"}`)}
+
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	hook.URL = srv.URL
+
+	code, err := NewSender(time.Second).Send(context.Background(), hook, d)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("send: %d %v", code, err)
+	}
+
+	if got.Method != http.MethodPost || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request %s with content type %q", got.Method, got.Header.Get("Content-Type"))
+	}
+	if got.Header.Get(HeaderID) != "delivery-1" || got.Header.Get(HeaderEvent) != "user.created" {
+		t.Errorf("id %q event %q", got.Header.Get(HeaderID), got.Header.Get(HeaderEvent))
+	}
+	if string(body) != `{"id":"1"}` {
+		t.Errorf("body %q", body)
+	}
+
+	timestamp, err := strconv.ParseInt(got.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age < -time.Second || age > time.Minute {
+		t.Errorf("timestamp %d is off by %v", timestamp, age)
+	}
+	if !Verify("whsec_test", timestamp, body, got.Header.Get(HeaderSignature)) {
+		t.Errorf("signature %q does not verify", got.Header.Get(HeaderSignature))
+	}
+}
+
+func TestSendFailsOnNon2xx(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusInternalServerError, "boom\n"},
+		{http.StatusBadRequest, strings.Repeat("x", 2*maxErrorBody)},
+		// Redirects are not followed, the 3xx itself is the answer
+		{http.StatusFound, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(strconv.Itoa(c.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c.status == http.StatusFound {
+					w.Header().Set("Location", "/moved")
+				}
+				w.WriteHeader(c.status)
+				io.WriteString(w, c.body)
+			}))
+			defer srv.Close()
+
+			code, err := NewSender(time.Second).Send(context.Background(), &domain.Webhook{URL: srv.URL}, &domain.WebhookDelivery{})
+			if code != c.status {
+				t.Fatalf("code %d, want %d", code, c.status)
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+
+			msg := err.Error()
+			if !strings.HasPrefix(msg, "HTTP "+strconv.Itoa(c.status)) {
+				t.Errorf("error %q", msg)
+			}
+			if len(msg) > maxErrorBody+len("HTTP 000: ") {
+				t.Errorf("error keeps %d bytes of the body", len(msg))
+			}
+		})
+	}
+}
+
+func TestSendReportsTransportErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	code, err := NewSender(50*time.Millisecond).Send(context.Background(), &domain.Webhook{URL: srv.URL}, &domain.WebhookDelivery{})
+	if err == nil || code != 0 {
+		t.Fatalf("timed out send: %d %v", code, err)
+	}
+}
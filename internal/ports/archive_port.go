@@ -0,0 +1,45 @@
+// Package ports
+// This one has the audit archival ports
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// ArchiveStorage stores archive files by key, keys use / as separator
+type ArchiveStorage interface {
+	// Create opens a new object for writing, it only becomes visible once Close succeeds
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+
+	// Open reads an object back
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// List returns the keys under prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+type AuditArchiveRepository interface {
+	// ExpiredPartitions lists partitions that end at or before before, oldest first
+	ExpiredPartitions(ctx context.Context, before time.Time) ([]domain.AuditPartition, error)
+
+	// StreamPartition reads every row of the partition in chain order
+	StreamPartition(ctx context.Context, p domain.AuditPartition, fn func(*domain.Audit) error) error
+
+	// DropPartition deletes the partition and its rows
+	DropPartition(ctx context.Context, p domain.AuditPartition) error
+
+	// ImportArchived stores archived rows outside the live chain, returns how many were new
+	ImportArchived(ctx context.Context, archive string, logs []domain.Audit) (int64, error)
+}
+
+type AuditArchiver interface {
+	// ArchiveExpired exports partitions past retention and drops them once the export is verified
+	ArchiveExpired(ctx context.Context) ([]*domain.ArchiveManifest, error)
+
+	// Import verifies the archive behind a manifest and loads its rows for investigation
+	Import(ctx context.Context, manifestKey string) (*domain.ArchiveManifest, int64, error)
+}
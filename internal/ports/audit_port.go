@@ -2,19 +2,83 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 )
 
 type AuditRepository interface {
+	// Create appends the entry to the hash chain and persists it
 	Create(ctx context.Context, auditLog domain.Audit) error
+
+	// CreateBatch chains and persists the entries in order in one transaction, all or nothing
+	CreateBatch(ctx context.Context, logs []domain.Audit) error
+
+	// Query reads one page of audit entries matching the filter, newest first
+	Query(ctx context.Context, filter domain.AuditFilter) ([]*domain.Audit, error)
+}
+
+// AuditChainRepository reads the hash chain back and stores signed checkpoints
+type AuditChainRepository interface {
+	// Head reads the current tip of the chain
+	Head(ctx context.Context) (domain.ChainHead, error)
+
+	// Walk streams chained entries created in [from, to) in chain order
+	Walk(ctx context.Context, from, to time.Time, fn func(*domain.Audit) error) error
+
+	// CreateCheckpoint stores a signed checkpoint
+	CreateCheckpoint(ctx context.Context, cp *domain.Checkpoint) error
+
+	// LatestCheckpoint reads the most recent checkpoint
+	LatestCheckpoint(ctx context.Context) (*domain.Checkpoint, error)
+
+	// CheckpointBefore reads the newest checkpoint whose chain tip was created at or before at
+	CheckpointBefore(ctx context.Context, at time.Time) (*domain.Checkpoint, error)
+
+	// Checkpoints lists checkpoints whose chain tip was created in [from, to)
+	Checkpoints(ctx context.Context, from, to time.Time) ([]*domain.Checkpoint, error)
+}
+
+// DeadLetterQueue holds events subscribers could not handle
+type DeadLetterQueue interface {
+	// List reads dead letters after filter.AfterSeq, oldest first
+	List(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error)
+
+	// Replay republishes a dead letter to its original subject and removes it from the queue
+	Replay(ctx context.Context, seq uint64) (*domain.DeadLetter, error)
 }
 
-type AuditPublisher interface {
-	Publish(ctx context.Context, auditLog domain.Audit) error
+// AuditIngestMonitor reports how the audit worker keeps up with the stream
+type AuditIngestMonitor interface {
+	IngestStats(ctx context.Context) (domain.IngestStats, error)
 }
 
-type BackgroundWorker interface {
-	Start(ctx context.Context) error
-	Stop()
+// AuditStream follows audit events live as they are published
+type AuditStream interface {
+	// Subscribe delivers events from start on until ctx is done, then closes the channel
+	Subscribe(ctx context.Context, start domain.StreamStart) (<-chan domain.StreamedAudit, error)
+}
+
+type AuditService interface {
+	// Query validates the filter and returns one page of the audit log
+	Query(ctx context.Context, filter domain.AuditFilter) (*domain.AuditPage, error)
+
+	// Verify walks the hash chain over [from, to) and reports the first broken link
+	Verify(ctx context.Context, from, to time.Time) (*domain.ChainReport, error)
+
+	// Checkpoint signs the current chain tip, it is a no-op when nothing changed since the last one
+	Checkpoint(ctx context.Context) (*domain.Checkpoint, error)
+
+	// DeadLetters lists events a subscriber gave up on
+	DeadLetters(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error)
+
+	// ReplayDeadLetter delivers a dead letter again to the subscriber that failed it
+	ReplayDeadLetter(ctx context.Context, seq uint64) (*domain.DeadLetter, error)
+
+	// IngestStats reports ingestion lag and throughput
+	IngestStats(ctx context.Context) (domain.IngestStats, error)
+
+	// Stream follows new audit events matching the filter. From and afterSeq pick where it starts,
+	// To ends it. The channel closes when ctx is done or To is reached.
+	Stream(ctx context.Context, filter domain.AuditFilter, afterSeq uint64) (<-chan domain.StreamedAudit, error)
 }
@@ -7,7 +7,24 @@ import (
 	"time"
 )
 
+// CacheRepo is a key value cache, values are stored as JSON
 type CacheRepo interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Get decodes the value under key into dest, found is false when the key is missing or expired
+	Get(ctx context.Context, key string, dest interface{}) (found bool, err error)
+
 	Exists(ctx context.Context, key string) (bool, error)
+
+	// Delete removes the keys, missing ones are ignored
+	Delete(ctx context.Context, keys ...string) error
+
+	// SetNX sets the value only if key is not there yet and reports whether it did
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+
+	// Incr adds one to the counter under key, a missing key starts at zero and never expires
+	Incr(ctx context.Context, key string) (int64, error)
+
+	// Expire sets the time to live of key, false when the key does not exist
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
 }
@@ -0,0 +1,26 @@
+// Package ports
+// This one has the domain event bus ports
+package ports
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// EventHandler handles one event, an error has it redelivered and eventually dead lettered
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+// EventBus fans events out to independent subscribers, each with its own position and retries
+type EventBus interface {
+	EventPublisher
+
+	// Subscribe returns a worker feeding the handler every event whose type matches one of
+	// the patterns (see domain.MatchEventType). name identifies the durable consumer, so a
+	// restarted subscriber picks up where it left off.
+	Subscribe(name string, patterns []string, handler EventHandler) BackgroundWorker
+}
@@ -7,3 +7,9 @@ type PasswordHasher interface {
 	Hash(password string) (string, error)
 	Compare(hash string, plain string) bool
 }
+
+// Signer signs and verifies messages, adapter is in internal/secure directory
+type Signer interface {
+	Sign(msg []byte) (string, error)
+	Verify(msg []byte, signature string) bool
+}
@@ -0,0 +1,23 @@
+// Package ports
+// This one has the idempotency key store port
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// IdempotencyStore keeps responses of requests made with an Idempotency-Key
+type IdempotencyStore interface {
+	// Get returns the stored response, nil when the key was never completed or has expired
+	Get(ctx context.Context, key string) (*domain.IdempotentResponse, error)
+
+	// Save keeps the response for ttl
+	Save(ctx context.Context, key string, res domain.IdempotentResponse, ttl time.Duration) error
+
+	// Lock claims the key for an in-flight request. acquired is false while another request holds it,
+	// the lock expires after ttl so a crashed request doesn't block the key forever.
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), acquired bool, err error)
+}
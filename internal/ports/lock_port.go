@@ -0,0 +1,28 @@
+// Package ports
+// This one has the distributed lock port
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// Locker hands out cluster wide locks so a job runs on a single replica at a time
+type Locker interface {
+	// Acquire takes the named lock for ttl without waiting.
+	// acquired is false when another holder has it, the lock must be released once done.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (lock Lock, acquired bool, err error)
+}
+
+// Lock is a held lock. It expires after its ttl unless renewed, so a holder that dies frees it.
+type Lock interface {
+	// Token is the fencing token, larger than the token of every earlier holder of the key.
+	// Writes guarded by the lock can carry it so the storage rejects a holder that lost the lock.
+	Token() uint64
+
+	// Renew extends the lock by ttl, domain.ErrLockLost once it expired or was taken over
+	Renew(ctx context.Context, ttl time.Duration) error
+
+	// Release frees the lock if it is still held by this holder
+	Release(ctx context.Context) error
+}
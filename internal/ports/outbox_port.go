@@ -0,0 +1,27 @@
+// Package ports
+// This one has the transactional outbox ports
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+type OutboxRepository interface {
+	// Enqueue stores a message to be relayed
+	Enqueue(ctx context.Context, msg *domain.OutboxMessage) error
+
+	// Claim leases up to limit due messages so no other relay picks them up for the lease duration
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+
+	// MarkSent records a successful relay
+	MarkSent(ctx context.Context, id int64) error
+
+	// MarkFailed records a failed relay and when to try again
+	MarkFailed(ctx context.Context, id int64, reason string, next time.Time) error
+
+	// PurgeSent deletes relayed messages older than before, returns how many were deleted
+	PurgeSent(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,15 @@
+// Package ports
+// This one has the rate limiter port
+package ports
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// RateLimiter counts requests per key
+type RateLimiter interface {
+	// Allow spends one request of key's budget under limit and reports whether it was allowed
+	Allow(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error)
+}
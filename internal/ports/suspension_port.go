@@ -0,0 +1,29 @@
+// Package ports
+// This one has the suspension history ports
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+type SuspensionRepository interface {
+	// Create records a new open suspension
+	Create(ctx context.Context, s *domain.Suspension) error
+
+	// ReadActive reads the open suspension of a user
+	ReadActive(ctx context.Context, userID string) (*domain.Suspension, error)
+
+	// Lift closes an open suspension, liftedBy is nil when lifted by the system
+	Lift(ctx context.Context, id string, liftedBy *string, reason string) error
+
+	// History lists every suspension of a user, newest first
+	History(ctx context.Context, userID string) ([]*domain.Suspension, error)
+
+	// ListExpired lists open suspensions whose expiry is at or before now. Suspensions of users
+	// in the trash are left out, they can't be reinstated and would fill every batch.
+	// They are listed again once the user is restored.
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.Suspension, error)
+}
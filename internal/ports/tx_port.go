@@ -0,0 +1,20 @@
+// Package ports
+// This one has the transaction port
+package ports
+
+import "context"
+
+// TxManager groups repository calls into one unit of work. Repositories use the transaction
+// carried by the context they get, so fn passes its ctx on to them. An EventPublisher backed by
+// the outbox writes inside the transaction too, the in-memory one holds events until the commit.
+type TxManager interface {
+	// WithinTx commits when fn returns nil and rolls back otherwise. It may run fn more than once
+	// when the transaction collides with another one, so fn keeps its effects in the database.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// AfterCommit defers fn until the transaction in ctx commits, it runs right away outside one
+	AfterCommit(ctx context.Context, fn func())
+
+	// InTx reports whether ctx carries a transaction
+	InTx(ctx context.Context) bool
+}
@@ -5,6 +5,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 )
@@ -15,6 +16,9 @@ type UserRepository interface {
 	// Read active users take optional filtering return: list of users and error if any
 	ReadAll(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error)
 
+	// Stream walks the filtered users one by one from a database cursor, never selects secrets
+	Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error
+
 	// ReadOne reads a single active user
 	ReadOne(ctx context.Context, id string) (*domain.User, error)
 
@@ -25,14 +29,18 @@ type UserRepository interface {
 	Update(ctx context.Context, updates domain.UserUpdate) error
 
 	// SoftDelete soft deletes a user set deleted_at current and user_status = 'inactive'
+	// remembering the previous status in status_before_delete
 	SoftDelete(ctx context.Context, id string) error
 
-	// Restore restore a soft deleted user
-	Restore(ctx context.Context, id string) error
+	// Restore restore a soft deleted user with the given status
+	Restore(ctx context.Context, id string, status string) error
 
 	// Trash lets you read soft deleted users with optional filtering
 	Trash(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error)
 
+	// ListTrashedBefore lists users soft deleted before the cutoff, oldest first
+	ListTrashedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error)
+
 	// ReadOneDeleted lets you read a deleted user entity
 	ReadOneDeleted(ctx context.Context, id string) (*domain.User, error)
 
@@ -49,6 +57,9 @@ type UserService interface {
 	// ListUsers retrieves users based on filters provided in the request.
 	ListUsers(ctx context.Context, filters domain.UserFilter) ([]*domain.User, error)
 
+	// ExportUsers streams filtered users into fn and records an audit event, returns the row count
+	ExportUsers(ctx context.Context, req domain.UserExport, fn func(*domain.User) error) (int, error)
+
 	// GetUser retrieves a single active user by their unique ID.
 	GetUser(ctx context.Context, id string) (*domain.User, error)
 
@@ -69,4 +80,19 @@ type UserService interface {
 
 	// PermanentlyDeleteUser removes a user record from the database entirely.
 	PermanentlyDeleteUser(ctx context.Context, id string) error
+
+	// SuspendUser suspends an active user with a reason and an optional end date
+	SuspendUser(ctx context.Context, req domain.SuspendUser) (*domain.Suspension, error)
+
+	// UnsuspendUser lifts a suspension before it expires
+	UnsuspendUser(ctx context.Context, req domain.UnsuspendUser) (*domain.User, error)
+
+	// SuspensionHistory lists every suspension a user has had
+	SuspensionHistory(ctx context.Context, id string) ([]*domain.Suspension, error)
+
+	// ReinstateExpired lifts suspensions whose end date has passed, returns how many were lifted
+	ReinstateExpired(ctx context.Context) (int, error)
+
+	// PurgeTrash hard deletes users trashed longer than the retention period, or only reports them in a dry run
+	PurgeTrash(ctx context.Context, req domain.TrashPurge) (*domain.PurgeReport, error)
 }
@@ -0,0 +1,56 @@
+// Package ports
+// This one has the outgoing webhook ports
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *domain.Webhook) error
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	ReadOne(ctx context.Context, id string) (*domain.Webhook, error)
+	Update(ctx context.Context, update domain.WebhookUpdate) error
+	Delete(ctx context.Context, id string) error
+
+	// CreateDeliveries queues deliveries, an event already queued for a webhook is skipped
+	CreateDeliveries(ctx context.Context, list []*domain.WebhookDelivery) error
+
+	// ClaimDeliveries leases due pending deliveries of active webhooks, hiding them from other dispatchers for lease
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+
+	// RecordAttempt saves the outcome of a delivery attempt
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error
+
+	ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error)
+
+	// Redeliver puts a delivery back in the queue with a fresh set of attempts
+	Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error)
+}
+
+// WebhookSender posts a delivery to its webhook, signed with the webhook's secret
+type WebhookSender interface {
+	// Send returns the response status, err is set for transport failures and non 2xx responses
+	Send(ctx context.Context, hook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error)
+}
+
+type WebhookService interface {
+	// Create registers a webhook, a secret is generated when none is given
+	Create(ctx context.Context, hook domain.Webhook) (*domain.Webhook, error)
+	List(ctx context.Context) ([]*domain.Webhook, error)
+	Get(ctx context.Context, id string) (*domain.Webhook, error)
+	Update(ctx context.Context, update domain.WebhookUpdate) (*domain.Webhook, error)
+	Delete(ctx context.Context, id string) error
+
+	Deliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error)
+
+	// HandleEvent is the EventHandler fanning an event out to every subscribed webhook
+	HandleEvent(ctx context.Context, event domain.Event) error
+
+	// Dispatch sends due deliveries and schedules retries, it returns how many were attempted
+	Dispatch(ctx context.Context) (int, error)
+}
@@ -0,0 +1,25 @@
+// Package ports
+// This one has the background worker ports
+package ports
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+type BackgroundWorker interface {
+	// Start runs the worker and blocks until ctx is done. A returned error, or a panic, means it crashed.
+	Start(ctx context.Context) error
+
+	// Stop waits for in-flight work to finish
+	Stop()
+}
+
+// WorkerMonitor reports on supervised background workers
+type WorkerMonitor interface {
+	Workers() []domain.WorkerStatus
+
+	// Draining is true once shutdown has started
+	Draining() bool
+}
@@ -0,0 +1,42 @@
+// Package secure
+// this one signs audit checkpoints with the ECDSA key pair
+package secure
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+type ECDSASigner struct {
+	PrivateKey *ecdsa.PrivateKey
+	PublicKey  *ecdsa.PublicKey
+}
+
+// NewECDSASigner returns a signer, priv may be nil for verify only use
+func NewECDSASigner(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) *ECDSASigner {
+	return &ECDSASigner{
+		PrivateKey: priv,
+		PublicKey:  pub,
+	}
+}
+
+// Sign returns the base64 ASN.1 signature of sha256(msg)
+func (s *ECDSASigner) Sign(msg []byte) (string, error) {
+	digest := sha256.Sum256(msg)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.PrivateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (s *ECDSASigner) Verify(msg []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256(msg)
+	return ecdsa.VerifyASN1(s.PublicKey, digest[:], sig)
+}
@@ -0,0 +1,276 @@
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+const (
+	archiveFormat = "ndjson+gzip"
+	importBatch   = 500
+)
+
+type archiver struct {
+	repo            ports.AuditArchiveRepository
+	store           ports.ArchiveStorage
+	retentionMonths int
+}
+
+// NewArchiver returns the archiver for audit partitions older than retentionMonths
+func NewArchiver(repo ports.AuditArchiveRepository, store ports.ArchiveStorage, retentionMonths int) ports.AuditArchiver {
+	return &archiver{repo: repo, store: store, retentionMonths: retentionMonths}
+}
+
+// archiveRecord is one archived row. The file format outlives the domain struct, so it has its own tags.
+type archiveRecord struct {
+	ID        int64          `json:"id"`
+	UUID      string         `json:"uuid"`
+	EventType string         `json:"event_type"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	Payload   map[string]any `json:"payload,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ChainSeq  int64          `json:"chain_seq,omitempty"`
+	PrevHash  string         `json:"prev_hash,omitempty"`
+	Hash      string         `json:"hash,omitempty"`
+}
+
+// ArchiveExpired stops at the first failure, partitions after it stay in place for the next run
+func (a *archiver) ArchiveExpired(ctx context.Context) ([]*domain.ArchiveManifest, error) {
+	cutoff := time.Now().UTC().AddDate(0, -a.retentionMonths, 0)
+
+	parts, err := a.repo.ExpiredPartitions(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*domain.ArchiveManifest
+	for _, p := range parts {
+		m, err := a.archive(ctx, p)
+		if err != nil {
+			return done, fmt.Errorf("archiving %s: %w", p.Table, err)
+		}
+
+		// Only an archive that was read back and matched its manifest lets the partition go
+		if err := a.repo.DropPartition(ctx, p); err != nil {
+			return done, fmt.Errorf("dropping %s: %w", p.Table, err)
+		}
+
+		slog.Info("Audit partition archived", "partition", m.Partition, "rows", m.Rows, "bytes", m.Bytes, "data_key", m.DataKey)
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// archive writes the data file, verifies it and writes the manifest last.
+// A data file without a manifest is an unfinished archive and gets overwritten on the next run.
+func (a *archiver) archive(ctx context.Context, p domain.AuditPartition) (*domain.ArchiveManifest, error) {
+	prefix := "audit/" + p.Table + "/"
+	m := &domain.ArchiveManifest{
+		Partition:  p.Schema + "." + p.Table,
+		From:       p.From,
+		To:         p.To,
+		Format:     archiveFormat,
+		DataKey:    prefix + "data.ndjson.gz",
+		ArchivedAt: time.Now().UTC(),
+	}
+
+	w, err := a.store.Create(ctx, m.DataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(w, sum)}
+	gz := gzip.NewWriter(cw)
+	enc := json.NewEncoder(gz)
+
+	err = a.repo.StreamPartition(ctx, p, func(row *domain.Audit) error {
+		m.Rows++
+		if row.ChainSeq > 0 {
+			if m.FirstSeq == 0 {
+				m.FirstSeq = row.ChainSeq
+			}
+			m.LastSeq = row.ChainSeq
+		}
+		return enc.Encode(archiveRecord{
+			ID:        row.ID,
+			UUID:      row.UUID,
+			EventType: row.EventType,
+			ActorID:   row.ActorID,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt,
+			ChainSeq:  row.ChainSeq,
+			PrevHash:  row.PrevHash,
+			Hash:      row.Hash,
+		})
+	})
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.Bytes = cw.n
+	m.SHA256 = hex.EncodeToString(sum.Sum(nil))
+
+	if err := a.verify(ctx, m); err != nil {
+		return nil, err
+	}
+
+	mw, err := a.store.Create(ctx, prefix+"manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	enc = json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		mw.Close()
+		return nil, err
+	}
+	return m, mw.Close()
+}
+
+// verify reads the data file back and checks it against the manifest
+func (a *archiver) verify(ctx context.Context, m *domain.ArchiveManifest) error {
+	return a.read(ctx, m, func(archiveRecord) error { return nil })
+}
+
+// read decodes every record of the data file, the checksum and row count are checked once the file is consumed
+func (a *archiver) read(ctx context.Context, m *domain.ArchiveManifest, fn func(archiveRecord) error) error {
+	r, err := a.store.Open(ctx, m.DataKey)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	sum := sha256.New()
+	cr := &countingReader{r: io.TeeReader(bufio.NewReader(r), sum)}
+	gz, err := gzip.NewReader(cr)
+	if err != nil {
+		return err
+	}
+	// The archive is one gzip member, trailing bytes are left for the checksum to catch
+	gz.Multistream(false)
+
+	var rows int64
+	dec := json.NewDecoder(gz)
+	for {
+		var rec archiveRecord
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows++
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	// Anything after the gzip stream still counts towards the checksum
+	if _, err := io.Copy(io.Discard, cr); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(sum.Sum(nil)); got != m.SHA256 || cr.n != m.Bytes {
+		return fmt.Errorf("archive %s checksum mismatch: manifest %s (%d bytes), file %s (%d bytes)", m.DataKey, m.SHA256, m.Bytes, got, cr.n)
+	}
+	if rows != m.Rows {
+		return fmt.Errorf("archive %s has %d rows, manifest says %d", m.DataKey, rows, m.Rows)
+	}
+	return nil
+}
+
+// Import verifies the whole file before loading anything, then loads it in batches
+func (a *archiver) Import(ctx context.Context, manifestKey string) (*domain.ArchiveManifest, int64, error) {
+	r, err := a.store.Open(ctx, manifestKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	m := &domain.ArchiveManifest{}
+	err = json.NewDecoder(r).Decode(m)
+	r.Close()
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading manifest: %w", err)
+	}
+	if m.Format != archiveFormat {
+		return nil, 0, fmt.Errorf("unsupported archive format %q", m.Format)
+	}
+
+	if err := a.verify(ctx, m); err != nil {
+		return m, 0, err
+	}
+
+	var imported int64
+	batch := make([]domain.Audit, 0, importBatch)
+	flush := func() error {
+		n, err := a.repo.ImportArchived(ctx, m.Partition, batch)
+		imported += n
+		batch = batch[:0]
+		return err
+	}
+
+	err = a.read(ctx, m, func(rec archiveRecord) error {
+		batch = append(batch, domain.Audit{
+			ID:        rec.ID,
+			UUID:      rec.UUID,
+			EventType: rec.EventType,
+			ActorID:   rec.ActorID,
+			Payload:   rec.Payload,
+			CreatedAt: rec.CreatedAt,
+			ChainSeq:  rec.ChainSeq,
+			PrevHash:  rec.PrevHash,
+			Hash:      rec.Hash,
+		})
+		if len(batch) == importBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+
+	return m, imported, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
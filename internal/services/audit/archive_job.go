@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// archiveLock keeps replicas from exporting the same partition twice
+const archiveLock = "jobs:audit-archive"
+
+type archiveJob struct {
+	archiver ports.AuditArchiver
+	locker   ports.Locker
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewArchiveJob returns a worker that archives and drops expired audit partitions
+func NewArchiveJob(archiver ports.AuditArchiver, locker ports.Locker, interval time.Duration) ports.BackgroundWorker {
+	return &archiveJob{archiver: archiver, locker: locker, interval: interval}
+}
+
+func (w *archiveJob) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *archiveJob) run(ctx context.Context) {
+	lock, acquired, err := w.locker.Acquire(ctx, archiveLock, w.interval)
+	if err != nil {
+		slog.Error("Audit archive lock failed", "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer lock.Release(ctx)
+
+	done, err := w.archiver.ArchiveExpired(ctx)
+	if err != nil {
+		slog.Error("Audit archive failed", "archived", len(done), "error", err)
+		return
+	}
+	if len(done) > 0 {
+		slog.Info("Audit partitions archived", "count", len(done))
+	}
+}
+
+func (w *archiveJob) Stop() { w.wg.Wait() }
@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/localfs"
+)
+
+// fakeArchiveRepo keeps partitions and the import table in memory
+type fakeArchiveRepo struct {
+	parts      []domain.AuditPartition
+	rows       map[string][]*domain.Audit
+	dropped    []string
+	imported   map[string]domain.Audit
+	importArgs []string
+	streamErr  error
+}
+
+func newFakeArchiveRepo() *fakeArchiveRepo {
+	return &fakeArchiveRepo{rows: map[string][]*domain.Audit{}, imported: map[string]domain.Audit{}}
+}
+
+// addPartition adds a month partition with n rows, the first unchained rows predate the hash chain
+func (r *fakeArchiveRepo) addPartition(from time.Time, n, unchained int) domain.AuditPartition {
+	p := domain.AuditPartition{Schema: "public", Table: "audit_log_p" + from.Format("20060102"), From: from, To: from.AddDate(0, 1, 0)}
+	r.parts = append(r.parts, p)
+
+	var prev string
+	for i := range n {
+		a := &domain.Audit{
+			ID:        int64(i + 1),
+			UUID:      fmt.Sprintf("%s-%d", p.Table, i),
+			EventType: "user.updated",
+			Payload:   map[string]any{"i": float64(i), "name": "n"},
+			CreatedAt: from.Add(time.Duration(i) * time.Second),
+		}
+		if i%2 == 0 {
+			a.ActorID = "0192f0c4-0000-7000-8000-000000000001"
+		}
+		if i >= unchained {
+			a.ChainSeq = int64(i + 1)
+			a.PrevHash = prev
+			a.Hash = fmt.Sprintf("hash-%d", i)
+			prev = a.Hash
+		}
+		r.rows[p.Table] = append(r.rows[p.Table], a)
+	}
+	return p
+}
+
+func (r *fakeArchiveRepo) ExpiredPartitions(ctx context.Context, before time.Time) ([]domain.AuditPartition, error) {
+	var list []domain.AuditPartition
+	for _, p := range r.parts {
+		if !p.To.After(before) {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeArchiveRepo) StreamPartition(ctx context.Context, p domain.AuditPartition, fn func(*domain.Audit) error) error {
+	for _, a := range r.rows[p.Table] {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return r.streamErr
+}
+
+func (r *fakeArchiveRepo) DropPartition(ctx context.Context, p domain.AuditPartition) error {
+	r.dropped = append(r.dropped, p.Table)
+	return nil
+}
+
+func (r *fakeArchiveRepo) ImportArchived(ctx context.Context, archive string, logs []domain.Audit) (int64, error) {
+	r.importArgs = append(r.importArgs, archive)
+	var n int64
+	for _, a := range logs {
+		if _, ok := r.imported[a.UUID]; !ok {
+			r.imported[a.UUID] = a
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newTestArchiver(t *testing.T) (*archiver, *fakeArchiveRepo, string) {
+	dir := t.TempDir()
+	store, err := localfs.NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newFakeArchiveRepo()
+	return NewArchiver(repo, store, 12).(*archiver), repo, dir
+}
+
+func TestArchiveImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	a, repo, _ := newTestArchiver(t)
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	big := repo.addPartition(old, importBatch*2+7, 3)
+	small := repo.addPartition(old.AddDate(0, 1, 0), 4, 0)
+	repo.addPartition(time.Now().UTC().AddDate(0, -1, 0), 2, 0) // inside retention
+
+	done, err := a.ArchiveExpired(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 || !reflect.DeepEqual(repo.dropped, []string{big.Table, small.Table}) {
+		t.Fatalf("archived %d, dropped %v", len(done), repo.dropped)
+	}
+
+	m := done[0]
+	if m.Rows != int64(importBatch*2+7) || m.FirstSeq != 4 || m.LastSeq != int64(importBatch*2+7) {
+		t.Fatalf("manifest rows %d seq %d..%d", m.Rows, m.FirstSeq, m.LastSeq)
+	}
+
+	manifestKey := "audit/" + big.Table + "/manifest.json"
+	got, imported, err := a.Import(ctx, manifestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != m.Rows || got.SHA256 != m.SHA256 {
+		t.Fatalf("imported %d of %d, sha %s want %s", imported, m.Rows, got.SHA256, m.SHA256)
+	}
+	for _, archive := range repo.importArgs {
+		if archive != "public."+big.Table {
+			t.Fatalf("imported under %q", archive)
+		}
+	}
+
+	for _, want := range repo.rows[big.Table] {
+		row, ok := repo.imported[want.UUID]
+		if !ok {
+			t.Fatalf("%s was not imported", want.UUID)
+		}
+		if !row.CreatedAt.Equal(want.CreatedAt) {
+			t.Fatalf("%s created_at %v, want %v", want.UUID, row.CreatedAt, want.CreatedAt)
+		}
+		row.CreatedAt = want.CreatedAt
+		if !reflect.DeepEqual(row, *want) {
+			t.Fatalf("row changed on the way through\ngot  %+v\nwant %+v", row, *want)
+		}
+	}
+
+	// A second import finds every row already there
+	if _, imported, err := a.Import(ctx, manifestKey); err != nil || imported != 0 {
+		t.Fatalf("reimport: %d rows, err %v", imported, err)
+	}
+}
+
+func TestArchiveKeepsPartitionWhenExportFails(t *testing.T) {
+	a, repo, dir := newTestArchiver(t)
+	p := repo.addPartition(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 3, 0)
+	repo.streamErr = errors.New("connection reset")
+
+	if _, err := a.ArchiveExpired(context.Background()); err == nil {
+		t.Fatal("archive succeeded")
+	}
+	if len(repo.dropped) != 0 {
+		t.Fatalf("dropped %v after a failed export", repo.dropped)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "audit", p.Table, "manifest.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("manifest written for a failed export: %v", err)
+	}
+}
+
+func TestImportRejectsChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		tamper func(data []byte) []byte
+	}{
+		{"trailing bytes", func(data []byte) []byte { return append(data, 0) }},
+		{"flipped byte", func(data []byte) []byte {
+			// gzip ignores the mtime in its header, only the manifest checksum notices
+			data[4] ^= 0xff
+			return data
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, repo, dir := newTestArchiver(t)
+			p := repo.addPartition(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 5, 0)
+			if _, err := a.ArchiveExpired(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			dataPath := filepath.Join(dir, "audit", p.Table, "data.ndjson.gz")
+			data, err := os.ReadFile(dataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(dataPath, tt.tamper(data), 0o640); err != nil {
+				t.Fatal(err)
+			}
+
+			_, imported, err := a.Import(ctx, "audit/"+p.Table+"/manifest.json")
+			if err == nil {
+				t.Fatal("tampered archive imported")
+			}
+			if imported != 0 || len(repo.imported) != 0 {
+				t.Fatalf("%d rows loaded from a tampered archive", len(repo.imported))
+			}
+			if !strings.Contains(err.Error(), "checksum mismatch") {
+				t.Fatalf("got %v, want a checksum mismatch", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package audit
+// This package handles reading the audit log back for compliance
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// defaultWindow is used when the caller gives no lower bound
+	defaultWindow = 30 * 24 * time.Hour
+	// maxWindow keeps a single query within about a year of monthly partitions
+	maxWindow = 366 * 24 * time.Hour
+)
+
+type service struct {
+	repo   ports.AuditRepository
+	chain  ports.AuditChainRepository
+	signer ports.Signer
+	dlq    ports.DeadLetterQueue
+	ingest ports.AuditIngestMonitor
+	stream ports.AuditStream
+}
+
+// NewAuditService wires the audit log readers. stream may be nil when no broker keeps the events.
+func NewAuditService(repo ports.AuditRepository, chain ports.AuditChainRepository, signer ports.Signer, dlq ports.DeadLetterQueue, ingest ports.AuditIngestMonitor, stream ports.AuditStream) ports.AuditService {
+	return &service{
+		repo:   repo,
+		chain:  chain,
+		signer: signer,
+		dlq:    dlq,
+		ingest: ingest,
+		stream: stream,
+	}
+}
+
+func (s *service) Query(ctx context.Context, filter domain.AuditFilter) (*domain.AuditPage, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultWindow)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "from must be before to",
+			Field:   "from",
+		}
+	}
+
+	if filter.To.Sub(filter.From) > maxWindow {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "Time range can not exceed 366 days",
+			Field:   "from",
+		}
+	}
+
+	if filter.PayloadValue != "" && filter.PayloadKey == "" {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "payload_value needs a payload_key",
+			Field:   "payload_key",
+		}
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	// Ask for one extra row to know whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	items, err := s.repo.Query(ctx, filter)
+	if err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "Audit query: Failed",
+			Err:     err,
+		}
+	}
+
+	page := &domain.AuditPage{Items: items}
+	if len(items) > pageSize {
+		page.Items = items[:pageSize]
+		last := page.Items[pageSize-1]
+		page.Next = &domain.AuditCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,232 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// errChainBroken stops the walk at the first bad link
+var errChainBroken = errors.New("audit chain broken")
+
+// Verify walks the chain over [from, to) in chain order. For every row it recomputes the
+// hash, checks that it links to the previous row and that no sequence number is missing.
+// The walk starts at the newest signed checkpoint at or before from, so the first row is
+// vouched for by a signature rather than by its own prev_hash, and without one it starts at
+// the very first row. Signed checkpoints in the range are checked against the rows they cover,
+// and when the range reaches the chain tip the last row has to be the one audit_chain_head names.
+func (s *service) Verify(ctx context.Context, from, to time.Time) (*domain.ChainReport, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultWindow)
+	}
+	if !from.Before(to) {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "from must be before to",
+			Field:   "from",
+		}
+	}
+
+	failed := func(err error) (*domain.ChainReport, error) {
+		return nil, &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "Audit verify: Failed",
+			Err:     err,
+		}
+	}
+
+	// Read before walking, rows appended meanwhile come after it
+	head, err := s.chain.Head(ctx)
+	if err != nil {
+		return failed(err)
+	}
+
+	anchor, err := s.chain.CheckpointBefore(ctx, from)
+	if err != nil && !isNotFound(err) {
+		return failed(err)
+	}
+	walkFrom := time.Time{}
+	if anchor != nil {
+		walkFrom = anchor.ChainAt
+	}
+
+	checkpoints, err := s.chain.Checkpoints(ctx, walkFrom, to)
+	if err != nil {
+		return failed(err)
+	}
+
+	report := &domain.ChainReport{From: from, To: to}
+
+	// Every row some other record vouches for, by sequence number
+	type expectation struct {
+		hash       string
+		source     string
+		checkpoint bool
+	}
+	expected := make(map[int64]expectation, len(checkpoints)+1)
+
+	// A checkpoint with a bad signature is a break on its own, whatever the rows say
+	for _, cp := range checkpoints {
+		if !s.signer.Verify(domain.CheckpointDigest(cp.Seq, cp.Hash, cp.ChainAt), cp.Signature) {
+			report.Broken = &domain.ChainBreak{Seq: cp.Seq, Reason: fmt.Sprintf("checkpoint %d has an invalid signature", cp.ID)}
+			return report, nil
+		}
+		expected[cp.Seq] = expectation{cp.Hash, fmt.Sprintf("signed checkpoint %d", cp.ID), true}
+	}
+	if head.Seq > 0 && head.CreatedAt.Before(to) {
+		if e, ok := expected[head.Seq]; ok && e.hash != head.Hash {
+			report.Broken = &domain.ChainBreak{Seq: head.Seq, Reason: "chain head differs from " + e.source}
+			return report, nil
+		}
+		expected[head.Seq] = expectation{head.Hash, "the chain head", false}
+	}
+
+	// Without a checkpoint the chain has to start from its first row
+	startSeq := int64(1)
+	if anchor != nil {
+		startSeq = anchor.Seq
+	}
+
+	var prev *domain.Audit
+	err = s.chain.Walk(ctx, walkFrom, to, func(a *domain.Audit) error {
+		// Rows sharing the anchor's timestamp but preceding it are outside the walk
+		if a.ChainSeq < startSeq {
+			return nil
+		}
+		if prev == nil {
+			if reason := checkStart(anchor, a); reason != "" {
+				report.Broken = &domain.ChainBreak{Seq: a.ChainSeq, AuditUUID: a.UUID, Reason: reason}
+				return errChainBroken
+			}
+		}
+		if reason := checkLink(prev, a); reason != "" {
+			report.Broken = &domain.ChainBreak{Seq: a.ChainSeq, AuditUUID: a.UUID, Reason: reason}
+			return errChainBroken
+		}
+
+		if e, ok := expected[a.ChainSeq]; ok {
+			if e.hash != a.Hash {
+				report.Broken = &domain.ChainBreak{Seq: a.ChainSeq, AuditUUID: a.UUID, Reason: "hash differs from " + e.source}
+				return errChainBroken
+			}
+			if e.checkpoint {
+				report.CheckpointsVerified++
+			}
+			delete(expected, a.ChainSeq)
+		}
+
+		if report.RowsChecked == 0 {
+			report.FirstSeq = a.ChainSeq
+		}
+		report.LastSeq = a.ChainSeq
+		report.RowsChecked++
+		prev = a
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return failed(err)
+	}
+
+	// A checkpoint covers the row created at the same instant, so a checkpoint or head in range
+	// whose row never showed up means that row was deleted
+	if report.Broken == nil && prev == nil && anchor != nil {
+		report.Broken = &domain.ChainBreak{Seq: anchor.Seq, Reason: fmt.Sprintf("row signed by checkpoint %d is missing", anchor.ID)}
+	}
+	if report.Broken == nil && len(expected) > 0 {
+		seq := int64(-1)
+		for n := range expected {
+			if seq < 0 || n < seq {
+				seq = n
+			}
+		}
+		report.Broken = &domain.ChainBreak{Seq: seq, Reason: "row named by " + expected[seq].source + " is missing"}
+	}
+
+	return report, nil
+}
+
+// checkStart returns why a can't open the walk, empty when it can. After a checkpoint the
+// walk opens on the signed row, without one on the first row of the chain.
+func checkStart(anchor *domain.Checkpoint, a *domain.Audit) string {
+	if anchor == nil {
+		if a.ChainSeq != 1 || a.PrevHash != "" {
+			return fmt.Sprintf("no checkpoint anchors the chain before row %d", a.ChainSeq)
+		}
+		return ""
+	}
+	if a.ChainSeq != anchor.Seq {
+		return fmt.Sprintf("row signed by checkpoint %d is missing", anchor.ID)
+	}
+	if a.Hash != anchor.Hash {
+		return fmt.Sprintf("hash differs from signed checkpoint %d", anchor.ID)
+	}
+	return ""
+}
+
+// checkLink returns why a does not correctly follow prev, empty when the link holds
+func checkLink(prev, a *domain.Audit) string {
+	hash, err := domain.ChainHash(a.PrevHash, *a)
+	if err != nil {
+		return "row content could not be hashed: " + err.Error()
+	}
+	if hash != a.Hash {
+		return "row content does not match its hash"
+	}
+
+	if prev == nil {
+		return ""
+	}
+	if a.ChainSeq != prev.ChainSeq+1 {
+		return fmt.Sprintf("rows %d to %d are missing", prev.ChainSeq+1, a.ChainSeq-1)
+	}
+	if a.PrevHash != prev.Hash {
+		return "prev_hash does not match the previous row"
+	}
+	return ""
+}
+
+// Checkpoint signs the current chain tip unless the latest checkpoint already covers it
+func (s *service) Checkpoint(ctx context.Context) (*domain.Checkpoint, error) {
+	head, err := s.chain.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.Seq == 0 {
+		return nil, nil
+	}
+
+	latest, err := s.chain.LatestCheckpoint(ctx)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if latest != nil && latest.Seq == head.Seq {
+		return nil, nil
+	}
+
+	sig, err := s.signer.Sign(domain.CheckpointDigest(head.Seq, head.Hash, head.CreatedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &domain.Checkpoint{
+		Seq:       head.Seq,
+		Hash:      head.Hash,
+		ChainAt:   head.CreatedAt,
+		Signature: sig,
+	}
+	if err := s.chain.CreateCheckpoint(ctx, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func isNotFound(err error) bool {
+	var appErr *domain.AppError
+	return errors.As(err, &appErr) && appErr.Code == domain.CodeNotFound
+}
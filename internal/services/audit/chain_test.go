@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// fakeSigner signs with a fixed prefix, enough to tell good signatures from bad ones
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(msg []byte) (string, error) { return "signed:" + string(msg), nil }
+func (fakeSigner) Verify(msg []byte, signature string) bool {
+	return signature == "signed:"+string(msg)
+}
+
+// fakeChain keeps the chain in memory, rows in chain order
+type fakeChain struct {
+	ports.AuditChainRepository
+	rows        []*domain.Audit
+	head        domain.ChainHead
+	checkpoints []*domain.Checkpoint
+}
+
+var chainStart = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// newFakeChain chains n rows a minute apart
+func newFakeChain(t *testing.T, n int) *fakeChain {
+	c := &fakeChain{}
+	for i := range n {
+		a := domain.Audit{
+			UUID:      "row-" + string(rune('a'+i)),
+			EventType: "test.event",
+			Payload:   map[string]any{"i": i},
+			CreatedAt: chainStart.Add(time.Duration(i) * time.Minute),
+			ChainSeq:  c.head.Seq + 1,
+			PrevHash:  c.head.Hash,
+		}
+		hash, err := domain.ChainHash(a.PrevHash, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Hash = hash
+		c.rows = append(c.rows, &a)
+		c.head = domain.ChainHead{Seq: a.ChainSeq, Hash: a.Hash, CreatedAt: a.CreatedAt}
+	}
+	return c
+}
+
+// checkpoint signs the row with seq
+func (c *fakeChain) checkpoint(seq int64) {
+	a := c.rows[seq-1]
+	sig, _ := fakeSigner{}.Sign(domain.CheckpointDigest(a.ChainSeq, a.Hash, a.CreatedAt))
+	c.checkpoints = append(c.checkpoints, &domain.Checkpoint{ID: int64(len(c.checkpoints) + 1), Seq: a.ChainSeq, Hash: a.Hash, ChainAt: a.CreatedAt, Signature: sig})
+}
+
+func (c *fakeChain) Head(ctx context.Context) (domain.ChainHead, error) { return c.head, nil }
+
+func (c *fakeChain) Walk(ctx context.Context, from, to time.Time, fn func(*domain.Audit) error) error {
+	for _, a := range c.rows {
+		if !a.CreatedAt.Before(from) && a.CreatedAt.Before(to) {
+			row := *a
+			if err := fn(&row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fakeChain) CheckpointBefore(ctx context.Context, at time.Time) (*domain.Checkpoint, error) {
+	var found *domain.Checkpoint
+	for _, cp := range c.checkpoints {
+		if !cp.ChainAt.After(at) {
+			found = cp
+		}
+	}
+	if found == nil {
+		return nil, &domain.AppError{Code: domain.CodeNotFound, Message: "no checkpoint"}
+	}
+	return found, nil
+}
+
+func (c *fakeChain) Checkpoints(ctx context.Context, from, to time.Time) ([]*domain.Checkpoint, error) {
+	var list []*domain.Checkpoint
+	for _, cp := range c.checkpoints {
+		if !cp.ChainAt.Before(from) && cp.ChainAt.Before(to) {
+			list = append(list, cp)
+		}
+	}
+	return list, nil
+}
+
+// rehash recomputes a tampered row's hash so only its links give it away
+func rehash(t *testing.T, a *domain.Audit) {
+	hash, err := domain.ChainHash(a.PrevHash, *a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Hash = hash
+}
+
+func TestVerify(t *testing.T) {
+	// Ten rows a minute apart, checkpoints at 3 and 7, verified from 05:00 to the end
+	from, to := chainStart.Add(5*time.Minute), chainStart.Add(time.Hour)
+
+	cases := []struct {
+		name   string
+		tamper func(t *testing.T, c *fakeChain)
+		broken string // empty for an intact chain
+	}{
+		{name: "intact chain", tamper: func(t *testing.T, c *fakeChain) {}},
+		{
+			name: "tail truncated below the head",
+			tamper: func(t *testing.T, c *fakeChain) {
+				c.rows = c.rows[:9]
+			},
+			broken: "row named by the chain head is missing",
+		},
+		{
+			name: "rows rewritten from the start of the range",
+			tamper: func(t *testing.T, c *fakeChain) {
+				// Row 6 opens the range, forged with a made up prev_hash and every later row relinked
+				prev := "forged"
+				for _, a := range c.rows[5:] {
+					a.PrevHash = prev
+					a.Payload = map[string]any{"forged": true}
+					rehash(t, a)
+					prev = a.Hash
+				}
+				c.head.Hash = prev
+			},
+			broken: "prev_hash does not match the previous row",
+		},
+		{
+			name: "anchor row deleted",
+			tamper: func(t *testing.T, c *fakeChain) {
+				c.rows = append(c.rows[:2:2], c.rows[3:]...)
+			},
+			broken: "row signed by checkpoint 1 is missing",
+		},
+		{
+			name: "checkpoint signature forged",
+			tamper: func(t *testing.T, c *fakeChain) {
+				c.checkpoints[1].Signature = "forged"
+			},
+			broken: "checkpoint 2 has an invalid signature",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := newFakeChain(t, 10)
+			chain.checkpoint(3)
+			chain.checkpoint(7)
+			tc.tamper(t, chain)
+
+			svc := &service{chain: chain, signer: fakeSigner{}}
+			report, err := svc.Verify(context.Background(), from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.broken == "" {
+				if report.Broken != nil {
+					t.Fatalf("intact chain reported broken: %+v", report.Broken)
+				}
+				// Walked from the checkpoint at 3 through the head at 10
+				if report.FirstSeq != 3 || report.LastSeq != 10 || report.CheckpointsVerified != 2 {
+					t.Fatalf("report %+v", report)
+				}
+				return
+			}
+			if report.Broken == nil {
+				t.Fatalf("not detected, report %+v", report)
+			}
+			if !strings.Contains(report.Broken.Reason, tc.broken) {
+				t.Fatalf("reason %q, want %q", report.Broken.Reason, tc.broken)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutCheckpointStartsAtTheFirstRow(t *testing.T) {
+	chain := newFakeChain(t, 4)
+	svc := &service{chain: chain, signer: fakeSigner{}}
+
+	report, err := svc.Verify(context.Background(), chainStart.Add(2*time.Minute), chainStart.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Broken != nil || report.FirstSeq != 1 || report.RowsChecked != 4 {
+		t.Fatalf("report %+v", report)
+	}
+
+	// Without an anchor the first rows can't be cut off unnoticed
+	chain.rows = chain.rows[1:]
+	report, err = svc.Verify(context.Background(), chainStart.Add(2*time.Minute), chainStart.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Broken == nil || report.Broken.Seq != 2 {
+		t.Fatalf("missing first row not detected: %+v", report)
+	}
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// checkpointLock keeps replicas from signing the same tip twice
+const checkpointLock = "jobs:audit-checkpoint"
+
+type checkpointer struct {
+	svc      ports.AuditService
+	locker   ports.Locker
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewCheckpointer returns a worker that periodically signs the audit chain tip
+func NewCheckpointer(svc ports.AuditService, locker ports.Locker, interval time.Duration) ports.BackgroundWorker {
+	return &checkpointer{svc: svc, locker: locker, interval: interval}
+}
+
+func (w *checkpointer) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *checkpointer) run(ctx context.Context) {
+	lock, acquired, err := w.locker.Acquire(ctx, checkpointLock, w.interval)
+	if err != nil {
+		slog.Error("Audit checkpoint lock failed", "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer lock.Release(ctx)
+
+	cp, err := w.svc.Checkpoint(ctx)
+	if err != nil {
+		slog.Error("Audit checkpoint failed", "error", err)
+		return
+	}
+	if cp != nil {
+		slog.Info("Audit checkpoint signed", "seq", cp.Seq, "hash", cp.Hash)
+	}
+}
+
+func (w *checkpointer) Stop() { w.wg.Wait() }
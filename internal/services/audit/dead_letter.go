@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+func (s *service) DeadLetters(ctx context.Context, filter domain.DeadLetterFilter) ([]*domain.DeadLetter, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	return s.dlq.List(ctx, filter)
+}
+
+func (s *service) ReplayDeadLetter(ctx context.Context, seq uint64) (*domain.DeadLetter, error) {
+	dl, err := s.dlq.Replay(ctx, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Dead letter replayed", "seq", seq, "subject", dl.Subject, "reason", dl.Reason, "actor_id", domain.ActorFromContext(ctx))
+	return dl, nil
+}
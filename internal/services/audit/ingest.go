@@ -0,0 +1,11 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+func (s *service) IngestStats(ctx context.Context) (domain.IngestStats, error) {
+	return s.ingest.IngestStats(ctx)
+}
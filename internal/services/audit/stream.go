@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+func (s *service) Stream(ctx context.Context, filter domain.AuditFilter, afterSeq uint64) (<-chan domain.StreamedAudit, error) {
+	if s.stream == nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeUnavailable,
+			Message: "live audit stream needs the nats events driver",
+		}
+	}
+
+	events, err := s.stream.Subscribe(ctx, domain.StreamStart{AfterSeq: afterSeq, From: filter.From})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan domain.StreamedAudit)
+	go func() {
+		defer close(out)
+		for e := range events {
+			if !filter.To.IsZero() && !e.PublishedAt.Before(filter.To) {
+				return
+			}
+			if !matches(filter, e.Audit) {
+				continue
+			}
+
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// matches applies the audit query filters to a single event in memory
+func matches(filter domain.AuditFilter, a domain.Audit) bool {
+	if filter.ActorID != "" && a.ActorID != filter.ActorID {
+		return false
+	}
+	if filter.EventType != "" && a.EventType != filter.EventType {
+		return false
+	}
+	if filter.PayloadKey != "" {
+		v, ok := a.Payload[filter.PayloadKey]
+		if !ok || !domain.NewPayloadMatch(filter.PayloadValue).Matches(v) {
+			return false
+		}
+	}
+	return true
+}
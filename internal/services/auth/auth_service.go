@@ -9,25 +9,34 @@ import (
 
 	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
 	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/AzmainMahtab/go-chi-hex/internal/services/events"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// RevokedRefreshPrefix prefixes the cache keys of revoked refresh tokens. The cache is the only
+// record of a revocation, so a cache that evicts must keep these.
+const RevokedRefreshPrefix = "blacklist:refresh:"
+
 type authService struct {
 	repo          ports.UserRepository
+	suspensions   ports.SuspensionRepository
 	tokenProvider ports.TokenProvider
 	cache         ports.CacheRepo
 	hasher        ports.PasswordHasher
-	auditPub      ports.AuditPublisher
+	events        ports.EventPublisher
+	tx            ports.TxManager
 }
 
-func NewAuthService(ur ports.UserRepository, tp ports.TokenProvider, c ports.CacheRepo, h ports.PasswordHasher, ap ports.AuditPublisher) ports.AuthService {
+func NewAuthService(ur ports.UserRepository, sr ports.SuspensionRepository, tp ports.TokenProvider, c ports.CacheRepo, h ports.PasswordHasher, events ports.EventPublisher, tx ports.TxManager) ports.AuthService {
 	return &authService{
 		repo:          ur,
+		suspensions:   sr,
 		tokenProvider: tp,
 		cache:         c,
 		hasher:        h,
-		auditPub:      ap,
+		events:        events,
+		tx:            tx,
 	}
 }
 
@@ -67,8 +76,15 @@ func (a *authService) Register(ctx context.Context, req domain.User) (*domain.Us
 	newUUID, _ := uuid.NewV7()
 	req.UUID = newUUID.String()
 
-	if err := a.repo.Create(ctx, &req); err != nil {
-		log.Printf("Service: Create user error: %v", err)
+	err = a.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := a.repo.Create(ctx, &req); err != nil {
+			log.Printf("Service: Create user error: %v", err)
+			return err
+		}
+		// Self registration, the new user is their own actor
+		return a.publish(ctx, req.UUID, domain.UserRegistered{UserChanged: domain.NewUserChanged(nil, &req)})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -76,7 +92,8 @@ func (a *authService) Register(ctx context.Context, req domain.User) (*domain.Us
 }
 
 func (a *authService) Login(ctx context.Context, login domain.AuthLogin) (domain.Tokenpair, error) {
-	u, err := a.repo.ReadByEmail(ctx, login.Email)
+	// The cache keeps no password hashes, the hash is read from the database
+	u, err := a.repo.ReadByEmail(domain.WithSecrets(ctx), login.Email)
 	if err != nil {
 		return domain.Tokenpair{}, &domain.AppError{
 			Code:    domain.CodeValidation,
@@ -85,51 +102,38 @@ func (a *authService) Login(ctx context.Context, login domain.AuthLogin) (domain
 		}
 	}
 
-	if u.UserStatus != "active" {
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(login.Password)); err != nil {
+
+		a.emit(ctx, u.UUID, domain.LoginAttempted{Email: u.Email, Status: "Failed"})
+
 		return domain.Tokenpair{}, &domain.AppError{
 			Code:    domain.CodeValidation,
-			Message: "Account suspended or inactive. contact admin",
+			Message: "One or more wrong credential",
 			Err:     err,
 		}
 	}
 
-	eventUUID, _ := uuid.NewV7()
-
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(login.Password)); err != nil {
-
-		a.auditPub.Publish(ctx, domain.Audit{
-			UUID:      eventUUID.String(),
-			EventType: "USER_LOGIN",
-			ActorID:   u.UUID,
-			Payload: map[string]any{
-				"email":  u.Email,
-				"status": "Failed",
-			},
-		})
+	// Status is only revealed once the password matched, the suspension
+	// reason is nobody's business who merely knows the email
+	if u.UserStatus == domain.StatusSuspended {
+		return domain.Tokenpair{}, a.suspendedError(ctx, u.UUID)
+	}
 
+	if u.UserStatus != domain.StatusActive {
 		return domain.Tokenpair{}, &domain.AppError{
 			Code:    domain.CodeValidation,
-			Message: "One or more wrong credential",
-			Err:     err,
+			Message: "Account suspended or inactive. contact admin",
 		}
 	}
 
-	a.auditPub.Publish(ctx, domain.Audit{
-		UUID:      eventUUID.String(),
-		EventType: "USER_LOGIN",
-		ActorID:   u.UUID,
-		Payload: map[string]any{
-			"email":  u.Email,
-			"status": "Success",
-		},
-	})
+	a.emit(ctx, u.UUID, domain.LoginAttempted{Email: u.Email, Status: "Success"})
 
 	return a.tokenProvider.GenerateTokenPair(u)
 }
 
 func (a *authService) Logout(ctx context.Context, refreshToken string, accessClaims domain.UserClaims) error {
 
-	exists, err := a.cache.Exists(ctx, "blacklist:refresh:"+refreshToken)
+	exists, err := a.cache.Exists(ctx, RevokedRefreshPrefix+refreshToken)
 	if err != nil {
 		return &domain.AppError{
 			Code:    domain.CodeInternal,
@@ -174,12 +178,12 @@ func (a *authService) Logout(ctx context.Context, refreshToken string, accessCla
 	}
 
 	//  Blacklist the token string
-	return a.cache.Set(ctx, "blacklist:refresh:"+refreshToken, "revoked", ttl)
+	return a.cache.Set(ctx, RevokedRefreshPrefix+refreshToken, "revoked", ttl)
 }
 
 func (a *authService) Rotate(ctx context.Context, refreshToken string) (domain.Tokenpair, error) {
 	// Checking if token already exist
-	blackList, err := a.cache.Exists(ctx, "blacklist:refresh:"+refreshToken)
+	blackList, err := a.cache.Exists(ctx, RevokedRefreshPrefix+refreshToken)
 	if err != nil {
 		return domain.Tokenpair{}, &domain.AppError{
 			Code:    domain.CodeInternal,
@@ -214,7 +218,11 @@ func (a *authService) Rotate(ctx context.Context, refreshToken string) (domain.T
 		}
 	}
 
-	if usr.UserStatus != "active" {
+	if usr.UserStatus == domain.StatusSuspended {
+		return domain.Tokenpair{}, a.suspendedError(ctx, usr.UUID)
+	}
+
+	if usr.UserStatus != domain.StatusActive {
 		return domain.Tokenpair{}, &domain.AppError{
 			Code:    domain.CodeInternal,
 			Message: "Uauthorized !",
@@ -235,7 +243,7 @@ func (a *authService) Rotate(ctx context.Context, refreshToken string) (domain.T
 	ttl := time.Until(expTime)
 
 	if ttl > 0 {
-		if err := a.cache.Set(ctx, "blacklist:refresh:"+refreshToken, "Rotated", ttl); err != nil {
+		if err := a.cache.Set(ctx, RevokedRefreshPrefix+refreshToken, "Rotated", ttl); err != nil {
 			return domain.Tokenpair{}, &domain.AppError{
 				Code:    domain.CodeInternal,
 				Message: "Something happened",
@@ -248,3 +256,37 @@ func (a *authService) Rotate(ctx context.Context, refreshToken string) (domain.T
 	return newToken, nil
 
 }
+
+// suspendedError builds the structured error telling a suspended user why and until when
+func (a *authService) suspendedError(ctx context.Context, userID string) error {
+	reason := "unspecified"
+	until := "indefinite"
+
+	// Best effort: the account stays locked even if the history lookup fails
+	if sus, err := a.suspensions.ReadActive(ctx, userID); err == nil {
+		reason = sus.Reason
+		if sus.ExpiresAt != nil {
+			until = sus.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return &domain.AppError{
+		Code:    domain.CodeSuspended,
+		Message: "Account suspended. contact admin",
+		Errors: []domain.ErrorItem{
+			{Field: "reason", Message: reason},
+			{Field: "suspended_until", Message: until},
+		},
+	}
+}
+
+// emit hands a domain event to the outbox. Events never fail the auth flow,
+// but a lost event is logged so it can be traced.
+func (a *authService) emit(ctx context.Context, actorID string, e domain.DomainEvent) {
+	events.Emit(ctx, a.events, actorID, e)
+}
+
+// publish returns the failure instead, for events written in the transaction of their change
+func (a *authService) publish(ctx context.Context, actorID string, e domain.DomainEvent) error {
+	return events.Publish(ctx, a.events, actorID, e)
+}
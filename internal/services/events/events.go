@@ -0,0 +1,39 @@
+// Package events
+// This one wraps domain events in their envelope and publishes them for every service
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/google/uuid"
+)
+
+// Publish wraps e in an envelope and publishes it. An empty actorID falls back to the acting
+// user carried in the request context. Events written in the transaction of their change use
+// this one, a failed publish has to roll the change back.
+func Publish(ctx context.Context, pub ports.EventPublisher, actorID string, e domain.DomainEvent) error {
+	if actorID == "" {
+		actorID = domain.ActorFromContext(ctx)
+	}
+
+	event, err := domain.NewEvent(e)
+	if err != nil {
+		return err
+	}
+	eventUUID, _ := uuid.NewV7()
+	event.ID = eventUUID.String()
+	event.ActorID = actorID
+	event.CorrelationID = domain.CorrelationIDFromContext(ctx)
+
+	return pub.Publish(ctx, event)
+}
+
+// Emit is Publish for events that never fail the action, a lost event is logged so it can be traced
+func Emit(ctx context.Context, pub ports.EventPublisher, actorID string, e domain.DomainEvent) {
+	if err := Publish(ctx, pub, actorID, e); err != nil {
+		slog.Error("Event publish failed", "event", e.EventType(), "actor_id", actorID, "error", err)
+	}
+}
@@ -0,0 +1,20 @@
+package users
+
+import (
+	"context"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/services/events"
+)
+
+// emit publishes a domain event, a failed publish is logged and never fails the action.
+// An empty actorID falls back to the acting user carried in the request context.
+func (s *service) emit(ctx context.Context, actorID string, e domain.DomainEvent) {
+	events.Emit(ctx, s.events, actorID, e)
+}
+
+// publish is emit for changes made in a transaction, the event goes into the same transaction
+// as the change it records so a failed publish has to roll the change back
+func (s *service) publish(ctx context.Context, actorID string, e domain.DomainEvent) error {
+	return events.Publish(ctx, s.events, actorID, e)
+}
@@ -0,0 +1,244 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/google/uuid"
+)
+
+// reinstateBatch caps how many expired suspensions one sweep handles
+const reinstateBatch = 100
+
+// errUserGone skips a suspension whose user was trashed after it was listed
+var errUserGone = errors.New("user is no longer active")
+
+// SuspendUser records a suspension in the history table and flips the user to suspended
+func (s *service) SuspendUser(ctx context.Context, req domain.SuspendUser) (*domain.Suspension, error) {
+	u, err := s.repo.ReadOne(domain.WithSecrets(ctx), req.UserID)
+	if err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "Resource Not found",
+			Err:     err,
+		}
+	}
+
+	if req.UserID == req.ActorID {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "You can not suspend yourself",
+		}
+	}
+
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "Suspension end date must be in the future",
+			Field:   "until",
+		}
+	}
+
+	if u.UserStatus == domain.StatusSuspended {
+		return nil, &domain.AppError{
+			Code:    domain.CodeConflict,
+			Message: "User is already suspended",
+			Field:   "status",
+		}
+	}
+
+	if err := domain.CheckStatusTransition(u.UserStatus, domain.StatusSuspended); err != nil {
+		return nil, err
+	}
+
+	newUUID, _ := uuid.NewV7()
+	sus := &domain.Suspension{
+		UUID:        newUUID.String(),
+		UserID:      req.UserID,
+		SuspendedBy: req.ActorID,
+		Reason:      req.Reason,
+		ExpiresAt:   req.Until,
+	}
+
+	after := *u
+	after.UserStatus = domain.StatusSuspended
+
+	event := domain.UserSuspended{
+		UserChanged:  domain.NewUserChanged(u, &after),
+		SuspensionID: sus.UUID,
+		Reason:       req.Reason,
+	}
+	if req.Until != nil {
+		until := req.Until.UTC()
+		event.Until = &until
+	}
+
+	// The history record, the status flip and the event land together or not at all
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.suspensions.Create(ctx, sus); err != nil {
+			return err
+		}
+
+		status := domain.StatusSuspended
+		if err := s.repo.Update(ctx, domain.UserUpdate{UUID: req.UserID, Status: &status}); err != nil {
+			return &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Action could not be performed",
+				Err:     err,
+			}
+		}
+
+		return s.publish(ctx, "", event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sus, nil
+}
+
+// UnsuspendUser closes the open suspension early and reactivates the user
+func (s *service) UnsuspendUser(ctx context.Context, req domain.UnsuspendUser) (*domain.User, error) {
+	u, err := s.repo.ReadOne(domain.WithSecrets(ctx), req.UserID)
+	if err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "Resource Not found",
+			Err:     err,
+		}
+	}
+
+	if u.UserStatus != domain.StatusSuspended {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "User is not suspended",
+			Field:   "status",
+		}
+	}
+
+	var updated *domain.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		event := domain.UserUnsuspended{Reason: req.Reason}
+
+		// Users suspended before the history table existed have no open record to close
+		open, err := s.suspensions.ReadActive(ctx, req.UserID)
+		switch {
+		case err == nil:
+			if err := s.suspensions.Lift(ctx, open.UUID, &req.ActorID, req.Reason); err != nil {
+				return err
+			}
+			event.SuspensionID = open.UUID
+		case !isNotFound(err):
+			return err
+		}
+
+		if err := s.reactivate(ctx, req.UserID); err != nil {
+			return err
+		}
+
+		updated, err = s.repo.ReadOne(ctx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		event.UserChanged = domain.NewUserChanged(u, updated)
+		return s.publish(ctx, "", event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// SuspensionHistory lists the suspension records of an existing user
+func (s *service) SuspensionHistory(ctx context.Context, id string) ([]*domain.Suspension, error) {
+	if _, err := s.repo.ReadOne(ctx, id); err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeNotFound,
+			Message: "Resource Not found",
+			Err:     err,
+		}
+	}
+
+	list, err := s.suspensions.History(ctx, id)
+	if err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "Action could not be performed",
+			Err:     err,
+		}
+	}
+	return list, nil
+}
+
+// ReinstateExpired lifts every due suspension and reactivates the user.
+// Failures are logged per user so one bad row does not block the rest of the sweep.
+func (s *service) ReinstateExpired(ctx context.Context) (int, error) {
+	due, err := s.suspensions.ListExpired(ctx, time.Now(), reinstateBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for _, sus := range due {
+		// A failed reinstatement rolls the lift back too, the next sweep picks the suspension up again
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			// Read first, a trashed user keeps the suspension open for when it is restored
+			before, err := s.repo.ReadOne(ctx, sus.UserID)
+			if isNotFound(err) {
+				return errUserGone
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := s.suspensions.Lift(ctx, sus.UUID, nil, "expired"); err != nil {
+				return err
+			}
+
+			if err := s.reactivate(ctx, sus.UserID); err != nil {
+				return err
+			}
+
+			after := *before
+			after.UserStatus = domain.StatusActive
+			return s.publish(ctx, "", domain.UserReinstated{
+				UserChanged:  domain.NewUserChanged(before, &after),
+				SuspensionID: sus.UUID,
+				Reason:       "expired",
+			})
+		})
+		if errors.Is(err, errUserGone) {
+			slog.Info("Suspension reinstatement skipped, user is in the trash", "suspension_id", sus.UUID, "user_id", sus.UserID)
+			continue
+		}
+		if err != nil {
+			slog.Error("Suspension reinstatement failed", "suspension_id", sus.UUID, "user_id", sus.UserID, "error", err)
+			continue
+		}
+		lifted++
+	}
+
+	return lifted, nil
+}
+
+func (s *service) reactivate(ctx context.Context, id string) error {
+	status := domain.StatusActive
+	if err := s.repo.Update(ctx, domain.UserUpdate{UUID: id, Status: &status}); err != nil {
+		return &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "Action could not be performed",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var appErr *domain.AppError
+	return errors.As(err, &appErr) && appErr.Code == domain.CodeNotFound
+}
@@ -0,0 +1,47 @@
+package users
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+type suspensionScheduler struct {
+	svc      ports.UserService
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewSuspensionScheduler returns a worker that reinstates users whose suspension has expired
+func NewSuspensionScheduler(svc ports.UserService, interval time.Duration) ports.BackgroundWorker {
+	return &suspensionScheduler{svc: svc, interval: interval}
+}
+
+func (w *suspensionScheduler) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n, err := w.svc.ReinstateExpired(ctx)
+			if err != nil {
+				slog.Error("Suspension sweep failed", "error", err)
+				continue
+			}
+			if n > 0 {
+				slog.Info("Reinstated users with expired suspensions", "count", n)
+			}
+		}
+	}
+}
+
+func (w *suspensionScheduler) Stop() { w.wg.Wait() }
@@ -0,0 +1,78 @@
+package users
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+)
+
+// purgeBatch caps how many trashed users one purge run handles
+const purgeBatch = 500
+
+// PurgeTrash hard deletes users trashed longer than the retention period.
+// In a dry run nothing is deleted, the report only lists the candidates.
+func (s *service) PurgeTrash(ctx context.Context, req domain.TrashPurge) (*domain.PurgeReport, error) {
+	if req.Retention <= 0 {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "Retention period must be positive",
+			Field:   "retention",
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > purgeBatch {
+		limit = purgeBatch
+	}
+
+	report := &domain.PurgeReport{
+		Cutoff: time.Now().Add(-req.Retention),
+		DryRun: req.DryRun,
+	}
+
+	candidates, err := s.repo.ListTrashedBefore(ctx, report.Cutoff, limit)
+	if err != nil {
+		return nil, &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "Trash purge: Failed",
+			Err:     err,
+		}
+	}
+	report.Candidates = candidates
+
+	if req.DryRun {
+		return report, nil
+	}
+
+	for _, u := range candidates {
+		// One transaction per user, a failure only keeps that user in the trash
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.Prune(ctx, u.UUID); err != nil {
+				return err
+			}
+			return s.publish(ctx, "", domain.UserPurged{
+				UserChanged: domain.NewUserChanged(u, nil),
+				Retention:   req.Retention.String(),
+				Trigger:     purgeTrigger(req),
+			})
+		})
+		if err != nil {
+			slog.Error("Trash purge failed for user", "user_id", u.UUID, "error", err)
+			report.Failed++
+			continue
+		}
+		report.Purged++
+	}
+
+	return report, nil
+}
+
+// purgeTrigger tells apart manual purges from the scheduled job in the audit trail
+func purgeTrigger(req domain.TrashPurge) string {
+	if req.ActorID == "" {
+		return "scheduled"
+	}
+	return "manual"
+}
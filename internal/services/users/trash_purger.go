@@ -0,0 +1,91 @@
+package users
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// trashPurgeLock is the cluster wide lock name, only its holder purges
+const trashPurgeLock = "jobs:trash-purge"
+
+type trashPurger struct {
+	svc       ports.UserService
+	locker    ports.Locker
+	interval  time.Duration
+	retention time.Duration
+	dryRun    bool
+	wg        sync.WaitGroup
+}
+
+// NewTrashPurger returns a worker that hard deletes users trashed longer than retention.
+// With dryRun set it only logs what it would delete.
+func NewTrashPurger(svc ports.UserService, locker ports.Locker, interval, retention time.Duration, dryRun bool) ports.BackgroundWorker {
+	return &trashPurger{
+		svc:       svc,
+		locker:    locker,
+		interval:  interval,
+		retention: retention,
+		dryRun:    dryRun,
+	}
+}
+
+func (w *trashPurger) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *trashPurger) run(ctx context.Context) {
+	lock, acquired, err := w.locker.Acquire(ctx, trashPurgeLock, w.interval)
+	if err != nil {
+		slog.Error("Trash purge lock failed", "error", err)
+		return
+	}
+	if !acquired {
+		// Another replica is purging
+		return
+	}
+	defer lock.Release(ctx)
+
+	report, err := w.svc.PurgeTrash(ctx, domain.TrashPurge{
+		Retention: w.retention,
+		DryRun:    w.dryRun,
+	})
+	if err != nil {
+		slog.Error("Trash purge failed", "error", err)
+		return
+	}
+
+	if w.dryRun {
+		ids := make([]string, len(report.Candidates))
+		for i, u := range report.Candidates {
+			ids[i] = u.UUID
+		}
+		if len(ids) > 0 {
+			slog.Info("Trash purge dry run", "cutoff", report.Cutoff, "would_purge", len(ids), "user_ids", ids)
+		}
+		return
+	}
+
+	if report.Purged > 0 || report.Failed > 0 {
+		slog.Info("Trash purge finished", "cutoff", report.Cutoff, "purged", report.Purged, "failed", report.Failed)
+	}
+}
+
+func (w *trashPurger) Stop() { w.wg.Wait() }
@@ -13,14 +13,20 @@ import (
 )
 
 type service struct {
-	repo   ports.UserRepository
-	hasher ports.PasswordHasher
+	repo        ports.UserRepository
+	suspensions ports.SuspensionRepository
+	hasher      ports.PasswordHasher
+	events      ports.EventPublisher
+	tx          ports.TxManager
 }
 
-func NewUserService(repo ports.UserRepository, hasher ports.PasswordHasher) ports.UserService {
+func NewUserService(repo ports.UserRepository, sr ports.SuspensionRepository, hasher ports.PasswordHasher, events ports.EventPublisher, tx ports.TxManager) ports.UserService {
 	return &service{
-		repo:   repo,
-		hasher: hasher,
+		repo:        repo,
+		suspensions: sr,
+		hasher:      hasher,
+		events:      events,
+		tx:          tx,
 	}
 }
 
@@ -61,8 +67,14 @@ func (s *service) RegisterUser(ctx context.Context, req domain.User) (*domain.Us
 	newUUID, _ := uuid.NewV7()
 	req.UUID = newUUID.String()
 
-	if err := s.repo.Create(ctx, &req); err != nil {
-		log.Printf("Service: Create user error: %v", err)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, &req); err != nil {
+			log.Printf("Service: Create user error: %v", err)
+			return err
+		}
+		return s.publish(ctx, "", domain.UserCreated{UserChanged: domain.NewUserChanged(nil, &req)})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -83,6 +95,46 @@ func (s *service) ListUsers(ctx context.Context, filters domain.UserFilter) ([]*
 	return users, nil
 }
 
+// ExportUsers streams the filtered roster to fn and raises an event recording who exported what
+func (s *service) ExportUsers(ctx context.Context, req domain.UserExport, fn func(*domain.User) error) (int, error) {
+	rows := 0
+	err := s.repo.Stream(ctx, req.Filter, func(u *domain.User) error {
+		// Belt and braces: secrets never leave the service even if a repo selects them
+		u.Password = ""
+		u.OTP = nil
+		rows++
+		return fn(u)
+	})
+
+	status := "Success"
+	if err != nil {
+		status = "Failed"
+	}
+
+	s.emit(ctx, req.ActorID, domain.UsersExported{
+		Format: req.Format,
+		Rows:   rows,
+		Status: status,
+		Filter: domain.UsersExportFilter{
+			UserName:    req.Filter.UserName,
+			Email:       req.Filter.Email,
+			Phone:       req.Filter.Phone,
+			UserStatus:  req.Filter.UserStatus,
+			ShowDeleted: req.Filter.ShowDeleted,
+		},
+	})
+
+	if err != nil {
+		return rows, &domain.AppError{
+			Code:    domain.CodeInternal,
+			Message: "User export: Failed",
+			Err:     err,
+		}
+	}
+
+	return rows, nil
+}
+
 func (s *service) GetUser(ctx context.Context, id string) (*domain.User, error) {
 	u, err := s.repo.ReadOne(ctx, id)
 	if err != nil {
@@ -123,8 +175,9 @@ func (s *service) GetUserByEmail(ctx context.Context, email string) (*domain.Use
 }
 
 func (s *service) UpdateUser(ctx context.Context, updates domain.UserUpdate) (*domain.User, error) {
-	// Check if user exists first (Optional, but good for business logic)
-	_, err := s.repo.ReadOne(ctx, updates.UUID)
+	// Check if user exists first (Optional, but good for business logic).
+	// With the secrets, the diff compares them against the updated row.
+	current, err := s.repo.ReadOne(domain.WithSecrets(ctx), updates.UUID)
 	if err != nil {
 		return nil, &domain.AppError{
 			Code:    domain.CodeNotFound,
@@ -133,22 +186,50 @@ func (s *service) UpdateUser(ctx context.Context, updates domain.UserUpdate) (*d
 		}
 	}
 
-	// Perform the partial update
-	if err := s.repo.Update(ctx, updates); err != nil {
-		slog.Error("Update err:", "err", err)
-		return nil, &domain.AppError{
-			Code:    domain.CodeInternal,
-			Message: "Action could not be performed",
-			Err:     err,
+	if updates.Status != nil && *updates.Status != current.UserStatus {
+		if err := domain.CheckStatusTransition(current.UserStatus, *updates.Status); err != nil {
+			return nil, err
+		}
+
+		// Suspensions carry a reason and history, so they only go through the suspension workflow
+		if *updates.Status == domain.StatusSuspended || current.UserStatus == domain.StatusSuspended {
+			return nil, &domain.AppError{
+				Code:    domain.CodeValidation,
+				Message: "Use the suspend and unsuspend endpoints to change suspension status",
+				Field:   "status",
+			}
 		}
 	}
 
-	// Return the fresh user data
-	return s.repo.ReadOne(ctx, updates.UUID)
+	var updated *domain.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Perform the partial update
+		if err := s.repo.Update(ctx, updates); err != nil {
+			slog.Error("Update err:", "err", err)
+			return &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Action could not be performed",
+				Err:     err,
+			}
+		}
+
+		// Return the fresh user data
+		updated, err = s.repo.ReadOne(ctx, updates.UUID)
+		if err != nil {
+			return err
+		}
+
+		return s.publish(ctx, "", domain.UserUpdated{UserChanged: domain.NewUserChanged(current, updated)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
 }
 
 func (s *service) RemoveUser(ctx context.Context, id string) error {
-	_, err := s.repo.ReadOne(ctx, id)
+	before, err := s.repo.ReadOne(domain.WithSecrets(ctx), id)
 	if err != nil {
 		return &domain.AppError{
 			Code:    domain.CodeNotFound,
@@ -157,20 +238,26 @@ func (s *service) RemoveUser(ctx context.Context, id string) error {
 		}
 	}
 
-	err = s.repo.SoftDelete(ctx, id)
-	if err != nil {
-		return &domain.AppError{
-			Code:    domain.CodeInternal,
-			Message: "Action could not be performed",
-			Err:     err,
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SoftDelete(ctx, id); err != nil {
+			return &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Action could not be performed",
+				Err:     err,
+			}
 		}
-	}
-	return nil
+
+		after, err := s.repo.ReadOneDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.publish(ctx, "", domain.UserDeleted{UserChanged: domain.NewUserChanged(before, after)})
+	})
 
 }
 
 func (s *service) RestoreUser(ctx context.Context, id string) (*domain.User, error) {
-	_, err := s.repo.ReadOneDeleted(ctx, id)
+	usr, err := s.repo.ReadOneDeleted(ctx, id)
 	if err != nil {
 		return nil, &domain.AppError{
 			Code:    domain.CodeNotFound,
@@ -179,16 +266,30 @@ func (s *service) RestoreUser(ctx context.Context, id string) (*domain.User, err
 		}
 	}
 
-	err = s.repo.Restore(ctx, id)
-	if err != nil {
-		return nil, &domain.AppError{
-			Code:    domain.CodeInternal,
-			Message: "Action could not be performed",
-			Err:     err,
+	// Trash parks users in inactive, restoring returns them to the status they had
+	// before deletion instead of walking the transition table from inactive
+	var restored *domain.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id, usr.RestoreStatus()); err != nil {
+			return &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Action could not be performed",
+				Err:     err,
+			}
+		}
+
+		restored, err = s.repo.ReadOne(ctx, id)
+		if err != nil {
+			return err
 		}
+
+		return s.publish(ctx, "", domain.UserRestored{UserChanged: domain.NewUserChanged(usr, restored)})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.ReadOne(ctx, id)
+	return restored, nil
 
 }
 
@@ -216,7 +317,7 @@ func (s *service) PermanentlyDeleteUser(ctx context.Context, id string) error {
 		}
 	}
 
-	if usr.UserStatus != "inactive" {
+	if usr.UserStatus != domain.StatusInactive {
 		return &domain.AppError{
 			Code:    domain.CodeValidation,
 			Message: "Can not delete active user",
@@ -224,14 +325,14 @@ func (s *service) PermanentlyDeleteUser(ctx context.Context, id string) error {
 		}
 	}
 
-	err = s.repo.Prune(ctx, id)
-	if err != nil {
-		return &domain.AppError{
-			Code:    domain.CodeInternal,
-			Message: "Action could not be performed",
-			Err:     err,
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Prune(ctx, id); err != nil {
+			return &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Action could not be performed",
+				Err:     err,
+			}
 		}
-	}
-
-	return nil
+		return s.publish(ctx, "", domain.UserPruned{UserChanged: domain.NewUserChanged(usr, nil)})
+	})
 }
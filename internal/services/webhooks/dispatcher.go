@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+type dispatcher struct {
+	svc       ports.WebhookService
+	interval  time.Duration
+	batchSize int
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher returns a worker that sends due webhook deliveries every interval.
+// A full batch is followed by the next one right away so a backlog drains quickly.
+func NewDispatcher(svc ports.WebhookService, interval time.Duration, batchSize int) ports.BackgroundWorker {
+	return &dispatcher{svc: svc, interval: interval, batchSize: batchSize}
+}
+
+func (w *dispatcher) Start(ctx context.Context) error {
+	w.wg.Add(1)
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *dispatcher) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := w.svc.Dispatch(ctx)
+		if err != nil {
+			slog.Error("Webhook dispatch failed", "error", err)
+			return
+		}
+		if n < w.batchSize {
+			return
+		}
+	}
+}
+
+func (w *dispatcher) Stop() { w.wg.Wait() }
@@ -0,0 +1,277 @@
+// Package webhooks
+// This package manages partner webhook subscriptions and delivers events to them
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	// dispatchLease hides a claimed delivery from other dispatchers, it has to outlast any send timeout
+	dispatchLease = 5 * time.Minute
+
+	retryBaseBackoff = 30 * time.Second
+	retryMaxBackoff  = 6 * time.Hour
+)
+
+type service struct {
+	repo        ports.WebhookRepository
+	sender      ports.WebhookSender
+	maxAttempts int
+	batchSize   int
+}
+
+// NewWebhookService returns the webhook service. A delivery is given up after maxAttempts,
+// Dispatch sends up to batchSize deliveries at a time.
+func NewWebhookService(repo ports.WebhookRepository, sender ports.WebhookSender, maxAttempts, batchSize int) ports.WebhookService {
+	return &service{
+		repo:        repo,
+		sender:      sender,
+		maxAttempts: maxAttempts,
+		batchSize:   batchSize,
+	}
+}
+
+func (s *service) Create(ctx context.Context, hook domain.Webhook) (*domain.Webhook, error) {
+	if err := validate(hook.URL, hook.EventTypes); err != nil {
+		return nil, err
+	}
+
+	if hook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, &domain.AppError{
+				Code:    domain.CodeInternal,
+				Message: "Webhook secret could not be generated",
+				Err:     err,
+			}
+		}
+		hook.Secret = secret
+	}
+
+	id, _ := uuid.NewV7()
+	hook.UUID = id.String()
+	hook.Active = true
+
+	if err := s.repo.Create(ctx, &hook); err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (s *service) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) Get(ctx context.Context, id string) (*domain.Webhook, error) {
+	return s.repo.ReadOne(ctx, id)
+}
+
+func (s *service) Update(ctx context.Context, update domain.WebhookUpdate) (*domain.Webhook, error) {
+	current, err := s.repo.ReadOne(ctx, update.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, eventTypes := current.URL, current.EventTypes
+	if update.URL != nil {
+		target = *update.URL
+	}
+	if update.EventTypes != nil {
+		eventTypes = update.EventTypes
+	}
+	if err := validate(target, eventTypes); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, update); err != nil {
+		return nil, err
+	}
+	return s.repo.ReadOne(ctx, update.UUID)
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Deliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		return nil, &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "limit may be at most 500",
+			Field:   "limit",
+		}
+	}
+
+	// Listing an unknown webhook is a 404, not an empty log
+	if _, err := s.repo.ReadOne(ctx, filter.WebhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, filter)
+}
+
+func (s *service) Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error) {
+	return s.repo.Redeliver(ctx, webhookID, deliveryID)
+}
+
+// HandleEvent() queues a delivery per subscribed webhook. The deliveries are written in
+// one statement, so a failed write is retried by the bus without duplicating any.
+func (s *service) HandleEvent(ctx context.Context, event domain.Event) error {
+	hooks, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	var deliveries []*domain.WebhookDelivery
+	for _, hook := range hooks {
+		if !hook.Wants(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+
+		id, _ := uuid.NewV7()
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			UUID:      id.String(),
+			WebhookID: hook.UUID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+		})
+	}
+
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// Dispatch() sends a batch of due deliveries concurrently and records every outcome
+func (s *service) Dispatch(ctx context.Context) (int, error) {
+	batch, err := s.repo.ClaimDeliveries(ctx, s.batchSize, dispatchLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// Claimed deliveries are finished even if shutdown starts meanwhile, the sender's timeout bounds them
+	send := context.WithoutCancel(ctx)
+
+	hooks := map[string]*domain.Webhook{}
+	var wg sync.WaitGroup
+	for _, d := range batch {
+		hook, ok := hooks[d.WebhookID]
+		if !ok {
+			if hook, err = s.repo.ReadOne(ctx, d.WebhookID); err != nil {
+				// Deleted since the claim, its deliveries went with it
+				slog.Warn("Webhook of claimed delivery not readable", "webhook_id", d.WebhookID, "error", err)
+				continue
+			}
+			hooks[d.WebhookID] = hook
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(send, hook, d)
+		}()
+	}
+	wg.Wait()
+
+	return len(batch), nil
+}
+
+// deliver makes one attempt and schedules the next one with exponential backoff
+func (s *service) deliver(ctx context.Context, hook *domain.Webhook, d *domain.WebhookDelivery) {
+	d.Attempts++
+	code, err := s.sender.Send(ctx, hook, d)
+
+	d.LastStatusCode = nil
+	if code != 0 {
+		d.LastStatusCode = &code
+	}
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		d.Status = domain.DeliverySucceeded
+		d.LastError = nil
+		d.DeliveredAt = &now
+	case d.Attempts >= s.maxAttempts:
+		reason := err.Error()
+		d.Status = domain.DeliveryFailed
+		d.LastError = &reason
+		slog.Error("Webhook delivery gave up", "webhook_id", hook.UUID, "delivery_id", d.UUID, "attempts", d.Attempts, "error", err)
+	default:
+		reason := err.Error()
+		d.Status = domain.DeliveryPending
+		d.LastError = &reason
+		d.NextAttemptAt = now.Add(backoff(d.Attempts))
+		slog.Warn("Webhook delivery failed, retrying", "webhook_id", hook.UUID, "delivery_id", d.UUID, "attempts", d.Attempts, "retry_at", d.NextAttemptAt, "error", err)
+	}
+
+	if err := s.repo.RecordAttempt(ctx, d); err != nil {
+		slog.Error("Recording webhook attempt failed", "delivery_id", d.UUID, "error", err)
+	}
+}
+
+// backoff doubles from retryBaseBackoff per attempt up to retryMaxBackoff
+func backoff(attempts int) time.Duration {
+	d := retryBaseBackoff << min(attempts-1, 20)
+	return min(d, retryMaxBackoff)
+}
+
+// validate checks what the database can't, the URL and the event patterns
+func validate(target string, eventTypes []string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "url must be an absolute http or https URL",
+			Field:   "url",
+		}
+	}
+
+	if len(eventTypes) == 0 {
+		return &domain.AppError{
+			Code:    domain.CodeValidation,
+			Message: "at least one event type is required",
+			Field:   "event_types",
+		}
+	}
+	for _, p := range eventTypes {
+		if !domain.ValidEventPattern(p) {
+			return &domain.AppError{
+				Code:    domain.CodeValidation,
+				Message: "invalid event type pattern: " + p,
+				Field:   "event_types",
+			}
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/infrastructure/webhook"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+// fakeRepo hands out the queued deliveries once and keeps the recorded attempts
+type fakeRepo struct {
+	ports.WebhookRepository
+	hooks    map[string]*domain.Webhook
+	queue    []*domain.WebhookDelivery
+	mu       sync.Mutex
+	attempts []domain.WebhookDelivery
+}
+
+func (r *fakeRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	batch := r.queue[:min(limit, len(r.queue))]
+	r.queue = r.queue[len(batch):]
+	return batch, nil
+}
+
+func (r *fakeRepo) ReadOne(ctx context.Context, id string) (*domain.Webhook, error) {
+	hook, ok := r.hooks[id]
+	if !ok {
+		return nil, &domain.AppError{Code: domain.CodeNotFound, Message: "Webhook not found"}
+	}
+	return hook, nil
+}
+
+func (r *fakeRepo) RecordAttempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, *d)
+	return nil
+}
+
+// endpoint answers every delivery with status and checks it was signed with secret
+func endpoint(t *testing.T, secret string, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify(secret, timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			t.Errorf("delivery %s is not signed with %s", r.Header.Get(webhook.HeaderID), secret)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestBackoffSchedule(t *testing.T) {
+	want := []time.Duration{
+		30 * time.Second,
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+		32 * time.Minute,
+		64 * time.Minute,
+		128 * time.Minute,
+		256 * time.Minute,
+		6 * time.Hour,
+	}
+	for i, w := range want {
+		if got := backoff(i + 1); got != w {
+			t.Errorf("attempt %d: %v, want %v", i+1, got, w)
+		}
+	}
+
+	// The shift is capped, very late attempts neither overflow nor go past the maximum
+	for _, attempts := range []int{30, 64, 1000} {
+		if got := backoff(attempts); got != retryMaxBackoff {
+			t.Errorf("attempt %d: %v, want %v", attempts, got, retryMaxBackoff)
+		}
+	}
+}
+
+func TestDispatchRecordsOutcomes(t *testing.T) {
+	ok, okCalls := endpoint(t, "whsec_ok", http.StatusOK)
+	down, downCalls := endpoint(t, "whsec_down", http.StatusServiceUnavailable)
+
+	repo := &fakeRepo{
+		hooks: map[string]*domain.Webhook{
+			"ok":   {UUID: "ok", URL: ok.URL, Secret: "whsec_ok"},
+			"down": {UUID: "down", URL: down.URL, Secret: "whsec_down"},
+		},
+		queue: []*domain.WebhookDelivery{
+			{UUID: "d1", WebhookID: "ok", Payload: []byte(`{}`)},
+			{UUID: "d2", WebhookID: "down", Payload: []byte(`{}`), Attempts: 2},
+			{UUID: "d3", WebhookID: "down", Payload: []byte(`{}`), Attempts: 4},
+			{UUID: "d4", WebhookID: "deleted", Payload: []byte(`{}`)},
+		},
+	}
+	svc := NewWebhookService(repo, webhook.NewSender(time.Second), 5, 10)
+
+	started := time.Now()
+	n, err := svc.Dispatch(context.Background())
+	if err != nil || n != 4 {
+		t.Fatalf("dispatch: %d %v", n, err)
+	}
+	if okCalls.Load() != 1 || downCalls.Load() != 2 {
+		t.Fatalf("endpoint calls: ok %d down %d", okCalls.Load(), downCalls.Load())
+	}
+
+	got := map[string]domain.WebhookDelivery{}
+	for _, a := range repo.attempts {
+		got[a.UUID] = a
+	}
+	if len(got) != 3 {
+		t.Fatalf("recorded %d attempts, the deleted webhook's delivery should be skipped", len(got))
+	}
+
+	if d := got["d1"]; d.Status != domain.DeliverySucceeded || d.Attempts != 1 || d.DeliveredAt == nil || d.LastError != nil {
+		t.Errorf("succeeded delivery: %+v", d)
+	}
+
+	// Third attempt of five, retried after backoff(3)
+	d := got["d2"]
+	if d.Status != domain.DeliveryPending || d.Attempts != 3 || d.LastError == nil {
+		t.Errorf("retried delivery: %+v", d)
+	}
+	if d.LastStatusCode == nil || *d.LastStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("retried delivery status code %v", d.LastStatusCode)
+	}
+	if wait := d.NextAttemptAt.Sub(started); wait < backoff(3) || wait > backoff(3)+time.Minute {
+		t.Errorf("retry scheduled in %v, want %v", wait, backoff(3))
+	}
+
+	// Fifth attempt of five, given up
+	if d := got["d3"]; d.Status != domain.DeliveryFailed || d.Attempts != 5 || d.LastError == nil {
+		t.Errorf("given up delivery: %+v", d)
+	}
+}
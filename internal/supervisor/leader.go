@@ -0,0 +1,105 @@
+// Package supervisor
+// This one runs a worker on a single replica at a time
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+type leader struct {
+	locker ports.Locker
+	key    string
+	ttl    time.Duration
+	worker ports.BackgroundWorker
+	wg     sync.WaitGroup
+}
+
+// Leader runs worker only while it holds the lock named key. Every replica keeps trying to take
+// the lock, the holder renews it every ttl/3. A holder that dies stops renewing, so another
+// replica takes over within ttl. A holder that fails to renew stops its worker before the
+// lock can expire under it and goes back to waiting.
+func Leader(locker ports.Locker, key string, ttl time.Duration, worker ports.BackgroundWorker) ports.BackgroundWorker {
+	return &leader{locker: locker, key: key, ttl: ttl, worker: worker}
+}
+
+func (l *leader) Start(ctx context.Context) error {
+	l.wg.Add(1)
+	defer l.wg.Done()
+
+	tick := l.ttl / 3
+	for {
+		lock, acquired, err := l.locker.Acquire(ctx, l.key, l.ttl)
+		if err != nil {
+			slog.Warn("Leader lock failed", "key", l.key, "error", err)
+		}
+		if acquired {
+			if err := l.lead(ctx, lock, tick); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(tick):
+		}
+	}
+}
+
+// lead runs the worker until ctx is done or the lock is lost, a crash of the worker is returned
+func (l *leader) lead(ctx context.Context, lock ports.Lock, tick time.Duration) error {
+	defer lock.Release(ctx)
+	slog.Info("Took leadership", "key", l.key, "token", lock.Token())
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- run(runCtx, l.worker) }()
+
+	// stop waits for the worker to drain before the lock goes
+	stop := func() {
+		cancel()
+		<-done
+		l.worker.Stop()
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			return nil
+		case err := <-done:
+			// The worker crashed, give the lock up so the supervisor's restart competes for it again
+			l.worker.Stop()
+			return err
+		case <-ticker.C:
+			err := lock.Renew(ctx, l.ttl)
+			if err == nil {
+				renewed = time.Now()
+				continue
+			}
+			// A failed renew is retried while the lock still has a tick left before it expires
+			if !errors.Is(err, domain.ErrLockLost) && time.Since(renewed)+tick < l.ttl {
+				slog.Warn("Leader lock renew failed, retrying", "key", l.key, "error", err)
+				continue
+			}
+			slog.Error("Lost leadership, stopping worker", "key", l.key, "token", lock.Token(), "error", err)
+			stop()
+			return nil
+		}
+	}
+}
+
+func (l *leader) Stop() { l.wg.Wait() }
@@ -0,0 +1,183 @@
+// Package supervisor
+// supervisor runs the background workers, restarts the ones that crash and drains them on shutdown
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AzmainMahtab/go-chi-hex/internal/domain"
+	"github.com/AzmainMahtab/go-chi-hex/internal/ports"
+)
+
+const (
+	restartBaseBackoff = time.Second
+	restartMaxBackoff  = time.Minute
+
+	// A worker that ran this long before crashing starts over from the base backoff
+	stableAfter = time.Minute
+)
+
+type entry struct {
+	name   string
+	worker ports.BackgroundWorker
+	cancel context.CancelFunc
+	done   chan struct{}
+	status domain.WorkerStatus
+}
+
+type Supervisor struct {
+	mu       sync.RWMutex
+	entries  []*entry
+	draining atomic.Bool
+}
+
+func New() *Supervisor {
+	return &Supervisor{}
+}
+
+// Add registers a worker. Workers are drained in the order they were added,
+// so add producers before the workers that consume what they produce.
+func (s *Supervisor) Add(name string, w ports.BackgroundWorker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, &entry{
+		name:   name,
+		worker: w,
+		done:   make(chan struct{}),
+		status: domain.WorkerStatus{Name: name, State: domain.WorkerStopped},
+	})
+}
+
+// Start runs every worker with its own cancellable context derived from ctx
+func (s *Supervisor) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		var wctx context.Context
+		wctx, e.cancel = context.WithCancel(ctx)
+		go s.supervise(wctx, e)
+	}
+}
+
+func (s *Supervisor) supervise(ctx context.Context, e *entry) {
+	defer close(e.done)
+
+	delay := restartBaseBackoff
+	for {
+		started := time.Now()
+		s.update(e, func(st *domain.WorkerStatus) {
+			st.State = domain.WorkerRunning
+			st.StartedAt = started
+		})
+
+		err := run(ctx, e.worker)
+		if ctx.Err() != nil {
+			e.worker.Stop()
+			s.update(e, func(st *domain.WorkerStatus) { st.State = domain.WorkerStopped })
+			slog.Info("Worker stopped", "worker", e.name)
+			return
+		}
+
+		if err == nil {
+			err = errors.New("worker returned before shutdown")
+		}
+		if time.Since(started) > stableAfter {
+			delay = restartBaseBackoff
+		}
+
+		s.update(e, func(st *domain.WorkerStatus) {
+			st.State = domain.WorkerRestarting
+			st.Restarts++
+			st.LastError = err.Error()
+		})
+		slog.Error("Worker crashed, restarting", "worker", e.name, "retry_in", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			s.update(e, func(st *domain.WorkerStatus) { st.State = domain.WorkerStopped })
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, restartMaxBackoff)
+	}
+}
+
+// run turns a panic in the worker into an error
+func run(ctx context.Context, w ports.BackgroundWorker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.Start(ctx)
+}
+
+// MarkDraining flags the shutdown early so health checks fail while the HTTP server drains
+func (s *Supervisor) MarkDraining() {
+	s.draining.Store(true)
+}
+
+// Shutdown stops the workers one by one in the order they were added.
+// It gives up once ctx is done and names the workers that did not drain in time.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.MarkDraining()
+
+	s.mu.RLock()
+	entries := s.entries
+	s.mu.RUnlock()
+
+	for i, e := range entries {
+		if e.cancel == nil {
+			continue
+		}
+
+		s.update(e, func(st *domain.WorkerStatus) { st.State = domain.WorkerDraining })
+		e.cancel()
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			// Out of time, cancel the rest anyway so they at least stop taking new work
+			var stuck []string
+			for _, rest := range entries[i:] {
+				if rest.cancel != nil {
+					rest.cancel()
+				}
+				stuck = append(stuck, rest.name)
+			}
+			return fmt.Errorf("workers not drained in time: %s", strings.Join(stuck, ", "))
+		}
+	}
+	return nil
+}
+
+// Workers snapshots the state of every worker
+func (s *Supervisor) Workers() []domain.WorkerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]domain.WorkerStatus, len(s.entries))
+	for i, e := range s.entries {
+		list[i] = e.status
+	}
+	return list
+}
+
+func (s *Supervisor) Draining() bool {
+	return s.draining.Load()
+}
+
+func (s *Supervisor) update(e *entry, fn func(*domain.WorkerStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&e.status)
+}
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "user_suspension"(
+  id SERIAL PRIMARY KEY,
+  uuid UUID UNIQUE NOT NULL,
+
+  user_id UUID NOT NULL REFERENCES "user" (uuid) ON DELETE CASCADE,
+  suspended_by UUID NOT NULL,
+  reason TEXT NOT NULL,
+
+  suspended_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMPTZ DEFAULT NULL,
+
+  -- lifted_by stays NULL when the scheduler reinstates on expiry
+  lifted_at TIMESTAMPTZ DEFAULT NULL,
+  lifted_by UUID DEFAULT NULL,
+  lift_reason TEXT DEFAULT NULL
+);
+
+--INDEXES HERE
+CREATE INDEX idx_user_suspension__user_id ON "user_suspension" (user_id, suspended_at DESC);
+-- A user can only have one open suspension at a time
+CREATE UNIQUE INDEX idx_user_suspension_unique_open ON "user_suspension" (user_id) WHERE lifted_at IS NULL;
+-- Lets the reinstatement sweep find due suspensions cheaply
+CREATE INDEX idx_user_suspension__expires_at ON "user_suspension" (expires_at) WHERE lifted_at IS NULL AND expires_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "user_suspension";
+-- +goose StatementEnd
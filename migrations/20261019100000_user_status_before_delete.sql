@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Remembers the status a user had when trashed so a restore brings it back
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS status_before_delete user_status_choise DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "user" DROP COLUMN IF EXISTS status_before_delete;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Indexes on the partitioned parent cascade to every monthly partition
+CREATE INDEX IF NOT EXISTS idx_audit_log__created_at_id ON "audit_log" (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log__actor_id ON "audit_log" (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log__event_type ON "audit_log" (event_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log__payload ON "audit_log" USING GIN (payload jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_log__payload;
+DROP INDEX IF EXISTS idx_audit_log__event_type;
+DROP INDEX IF EXISTS idx_audit_log__actor_id;
+DROP INDEX IF EXISTS idx_audit_log__created_at_id;
+-- +goose StatementEnd
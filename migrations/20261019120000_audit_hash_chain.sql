@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every row links to the previous one, chain_seq gives the order independently of partitions
+ALTER TABLE "audit_log" ADD COLUMN IF NOT EXISTS chain_seq BIGINT DEFAULT NULL;
+ALTER TABLE "audit_log" ADD COLUMN IF NOT EXISTS prev_hash TEXT DEFAULT NULL;
+ALTER TABLE "audit_log" ADD COLUMN IF NOT EXISTS hash TEXT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log__chain_seq ON "audit_log" (created_at, chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Single row holding the tip of the chain, locked FOR UPDATE by every writer.
+-- Kept outside audit_log so it survives partitions being created and dropped.
+CREATE TABLE IF NOT EXISTS "audit_chain_head"(
+  id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+  last_seq BIGINT NOT NULL DEFAULT 0,
+  last_hash TEXT NOT NULL DEFAULT '',
+  last_created_at TIMESTAMPTZ DEFAULT NULL
+);
+INSERT INTO "audit_chain_head" (id) VALUES (1) ON CONFLICT DO NOTHING;
+
+-- Periodic signed snapshots of the chain tip
+CREATE TABLE IF NOT EXISTS "audit_checkpoint"(
+  id SERIAL PRIMARY KEY,
+  chain_seq BIGINT NOT NULL,
+  chain_hash TEXT NOT NULL,
+  chain_created_at TIMESTAMPTZ NOT NULL,
+  signature TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoint__chain_created_at ON "audit_checkpoint" (chain_created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "audit_checkpoint";
+DROP TABLE IF EXISTS "audit_chain_head";
+DROP INDEX IF EXISTS idx_audit_log__chain_seq;
+ALTER TABLE "audit_log" DROP COLUMN IF EXISTS hash;
+ALTER TABLE "audit_log" DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE "audit_log" DROP COLUMN IF EXISTS chain_seq;
+-- +goose StatementEnd
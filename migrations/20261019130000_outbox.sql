@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "outbox"(
+  id BIGSERIAL PRIMARY KEY,
+  uuid UUID UNIQUE NOT NULL,
+
+  subject VARCHAR(128) NOT NULL,
+  payload JSONB NOT NULL,
+
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_error TEXT DEFAULT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  sent_at TIMESTAMPTZ DEFAULT NULL
+);
+
+--INDEXES HERE
+-- The relay only ever looks at unsent rows that are due
+CREATE INDEX idx_outbox__pending ON "outbox" (next_attempt_at, id) WHERE sent_at IS NULL;
+CREATE INDEX idx_outbox__sent_at ON "outbox" (sent_at) WHERE sent_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "outbox";
+-- +goose StatementEnd
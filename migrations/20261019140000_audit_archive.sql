@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The app archives expired partitions before dropping them (AUDIT_RETENTION_MONTHS),
+-- so partman must no longer drop them on its own
+UPDATE partman.part_config
+SET retention = NULL
+WHERE parent_table = 'public.audit_log';
+
+-- Archived rows re-imported for an investigation, kept apart from the live hash chain
+CREATE TABLE IF NOT EXISTS "audit_archive_import"(
+  id BIGINT NOT NULL,
+  uuid UUID PRIMARY KEY,
+  event_type VARCHAR(64) NOT NULL,
+  actor_id UUID,
+  payload JSONB,
+  created_at TIMESTAMPTZ NOT NULL,
+  chain_seq BIGINT DEFAULT NULL,
+  prev_hash TEXT DEFAULT NULL,
+  hash TEXT DEFAULT NULL,
+
+  archive VARCHAR(255) NOT NULL,
+  imported_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_archive_import__archive ON "audit_archive_import" (archive);
+CREATE INDEX IF NOT EXISTS idx_audit_archive_import__created_at ON "audit_archive_import" (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "audit_archive_import";
+
+UPDATE partman.part_config
+SET retention = '12 months',
+    retention_keep_table = false
+WHERE parent_table = 'public.audit_log';
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Audit events still waiting in the outbox are rewritten as versioned event envelopes
+-- so the relay publishes them on their per type subject like everything new.
+WITH legacy AS (
+  SELECT id,
+         payload,
+         CASE payload->>'EventType'
+           WHEN 'USER_EXPORT' THEN 'user.exported'
+           WHEN 'USER_LOGIN' THEN 'auth.login'
+           ELSE lower(regexp_replace(payload->>'EventType', '_', '.', 'g'))
+         END AS event_type
+  FROM "outbox"
+  WHERE subject = 'audit.event' AND sent_at IS NULL
+)
+UPDATE "outbox" o
+SET subject = 'events.' || l.event_type,
+    payload = jsonb_build_object(
+      'id', COALESCE(l.payload->>'UUID', o.uuid::text),
+      'type', l.event_type,
+      'version', 1,
+      'occurred_at', o.created_at,
+      'actor_id', NULLIF(l.payload->>'ActorID', ''),
+      'data', COALESCE(l.payload->'Payload', '{}'::jsonb)
+    )
+FROM legacy l
+WHERE o.id = l.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Envelopes are a superset of the old audit payload, nothing to undo
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "webhook"(
+  id BIGSERIAL PRIMARY KEY,
+  uuid UUID UNIQUE NOT NULL,
+
+  url TEXT NOT NULL,
+  -- Event type patterns, e.g. ["user.created", "user.*"]
+  event_types JSONB NOT NULL,
+  -- Kept in clear, it is needed to sign every delivery
+  secret TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_by UUID DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_delivery"(
+  id BIGSERIAL PRIMARY KEY,
+  uuid UUID UNIQUE NOT NULL,
+
+  webhook_id UUID NOT NULL REFERENCES "webhook" (uuid) ON DELETE CASCADE,
+  event_id UUID NOT NULL,
+  event_type VARCHAR(128) NOT NULL,
+  payload JSONB NOT NULL,
+
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_status_code INT DEFAULT NULL,
+  last_error TEXT DEFAULT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  delivered_at TIMESTAMPTZ DEFAULT NULL
+);
+
+--INDEXES HERE
+-- A redelivered event never fans out to the same webhook twice
+CREATE UNIQUE INDEX idx_webhook_delivery__event ON "webhook_delivery" (webhook_id, event_id);
+-- The dispatcher only ever looks at pending rows that are due
+CREATE INDEX idx_webhook_delivery__pending ON "webhook_delivery" (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX idx_webhook_delivery__log ON "webhook_delivery" (webhook_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "webhook_delivery";
+DROP TABLE IF EXISTS "webhook";
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Fencing tokens of the advisory locker, one sequence for every key keeps them increasing per key too
+CREATE SEQUENCE IF NOT EXISTS lock_fence_seq;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP SEQUENCE IF EXISTS lock_fence_seq;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Event ids already in the audit log. audit_log is partitioned by created_at, so it can't hold
+-- a unique index on uuid alone. Writers claim the id here before chaining an entry, which makes
+-- redelivered and replayed events a no-op. Rows outlive the partitions they point to, so an
+-- event replayed after its partition was archived still isn't stored twice.
+CREATE TABLE IF NOT EXISTS "audit_event_id"(
+  uuid UUID PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO "audit_event_id" (uuid, created_at)
+SELECT uuid, MIN(created_at) FROM "audit_log" GROUP BY uuid
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "audit_event_id";
+-- +goose StatementEnd
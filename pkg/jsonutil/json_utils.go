@@ -176,3 +176,8 @@ func ConflictResponse(w http.ResponseWriter, message string, errors []ErrorItem)
 func UnauthorizedResponse(w http.ResponseWriter, message string) {
 	ErrorResponse(w, http.StatusUnauthorized, message, nil)
 }
+
+// ForbiddenResponse() for authenticated callers lacking permission
+func ForbiddenResponse(w http.ResponseWriter, message string) {
+	ErrorResponse(w, http.StatusForbidden, message, nil)
+}